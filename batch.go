@@ -0,0 +1,302 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// BackpressurePolicy controls what a batching sink does
+// when its buffer of pending events is full
+type BackpressurePolicy uint8
+
+// Backpressure policies
+const (
+	// BackpressureDropOldest drops the oldest pending event
+	BackpressureDropOldest BackpressurePolicy = iota
+	// BackpressureDropNewest drops the event being written
+	BackpressureDropNewest
+	// BackpressureBlock blocks writes until there's space
+	// in the buffer
+	BackpressureBlock
+)
+
+// BatchStats contains statistics about a batching sink
+type BatchStats struct {
+	// Sent is the amount of events successfully sent
+	Sent uint64
+	// Pending is the amount of events waiting to be sent
+	Pending uint64
+	// Dropped is the amount of events dropped, either
+	// because of backpressure or because a batch
+	// couldn't be sent
+	Dropped uint64
+	// Retries is the amount of attempts that were retried
+	Retries uint64
+	// Errors is the amount of failed attempts
+	Errors uint64
+}
+
+// batchAttempt makes a single attempt at sending a batch. It reports
+// whether the attempt may be retried if it fails, and how long to
+// wait before retrying if the destination asked for a specific
+// delay. Otherwise, wait is negative and the backoff is used.
+type batchAttempt func() (wait time.Duration, retry bool, err error)
+
+// batchSender prepares a batch of encoded events to be sent
+// and returns a function that attempts to send it
+type batchSender func(batch [][]byte) (batchAttempt, error)
+
+// Batching queues encoded events and sends them in batches from a
+// background goroutine. It's embedded in the sinks that send events
+// in batches, such as HTTPWriter and FluentClient.
+//
+// Batches are sent when they reach BatchSize events or BatchBytes
+// bytes, when the flush interval elapses, or when Flush or Close
+// is called. If the flush interval isn't positive, it defaults to
+// five seconds. Failed batches are retried with exponential backoff.
+type Batching struct {
+	// BatchSize is the amount of events that
+	// triggers sending a batch
+	BatchSize int
+	// BatchBytes is the size of events in bytes that triggers
+	// sending a batch. Zero means no limit.
+	BatchBytes int
+
+	// MaxPending is the maximum amount of events kept
+	// in memory while waiting to be sent
+	MaxPending int
+	// Backpressure controls what happens when
+	// MaxPending is reached
+	Backpressure BackpressurePolicy
+
+	// MaxRetries is the maximum amount of times a failed
+	// batch will be retried before it's dropped
+	MaxRetries int
+	// InitialBackoff is the time to wait before the first retry.
+	// It doubles after every retry, up to MaxBackoff.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum time to wait between retries
+	MaxBackoff time.Duration
+
+	// ErrorHandler is called with any errors that occur while
+	// sending in the background, as well as with errors from
+	// adding events in the loggers that use the sink, such
+	// as ErrWriterClosed if the sink has been closed
+	ErrorHandler func(error)
+
+	sender batchSender
+
+	mu         sync.Mutex
+	cond       *sync.Cond
+	queue      [][]byte
+	queueBytes int
+	stats      BatchStats
+	closed     bool
+
+	sendMu  sync.Mutex
+	flushCh chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// defaultFlushInterval is used when a sink is
+// created with a flush interval that isn't positive
+const defaultFlushInterval = 5 * time.Second
+
+// startBatching starts a goroutine that sends pending
+// events using sender every flush interval
+func (b *Batching) startBatching(interval time.Duration, sender batchSender) {
+	if interval <= 0 {
+		interval = defaultFlushInterval
+	}
+	b.sender = sender
+	b.cond = sync.NewCond(&b.mu)
+	b.flushCh = make(chan struct{}, 1)
+	b.done = make(chan struct{})
+	b.stopped = make(chan struct{})
+	go b.run(interval)
+}
+
+// run sends pending events until the sink is closed
+func (b *Batching) run(interval time.Duration) {
+	defer close(b.stopped)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-b.flushCh:
+		case <-b.done:
+			return
+		}
+		b.handleError(b.Flush())
+	}
+}
+
+// handleError passes err to the error handler if it's not nil
+func (b *Batching) handleError(err error) {
+	if err != nil && b.ErrorHandler != nil {
+		b.ErrorHandler(err)
+	}
+}
+
+// enqueue adds an encoded event to the pending events. If
+// the buffer is full, the backpressure policy is applied.
+func (b *Batching) enqueue(event []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for !b.closed && b.MaxPending > 0 && len(b.queue) >= b.MaxPending {
+		switch b.Backpressure {
+		case BackpressureBlock:
+			b.cond.Wait()
+		case BackpressureDropNewest:
+			b.stats.Dropped++
+			return nil
+		default:
+			b.queueBytes -= len(b.queue[0])
+			b.queue = b.queue[1:]
+			b.stats.Dropped++
+		}
+	}
+	if b.closed {
+		return ErrWriterClosed
+	}
+
+	b.queue = append(b.queue, event)
+	b.queueBytes += len(event)
+
+	if len(b.queue) >= b.BatchSize || (b.BatchBytes > 0 && b.queueBytes >= b.BatchBytes) {
+		select {
+		case b.flushCh <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Stats returns statistics about the sink
+func (b *Batching) Stats() BatchStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stats := b.stats
+	stats.Pending = uint64(len(b.queue))
+	return stats
+}
+
+// Flush synchronously sends all pending events. If any batch
+// fails to send, the first error is returned.
+func (b *Batching) Flush() error {
+	b.sendMu.Lock()
+	defer b.sendMu.Unlock()
+
+	var firstErr error
+	for {
+		batch := b.nextBatch()
+		if len(batch) == 0 {
+			return firstErr
+		}
+
+		err := b.send(batch)
+
+		b.mu.Lock()
+		if err != nil {
+			b.stats.Dropped += uint64(len(batch))
+		} else {
+			b.stats.Sent += uint64(len(batch))
+		}
+		b.mu.Unlock()
+
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+}
+
+// Close stops the background goroutine and sends any
+// pending events. Writes after Close return ErrWriterClosed.
+func (b *Batching) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	close(b.done)
+	b.cond.Broadcast()
+	b.mu.Unlock()
+
+	<-b.stopped
+	return b.Flush()
+}
+
+// nextBatch removes the next batch of events from the queue
+func (b *Batching) nextBatch() [][]byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n, size := 0, 0
+	for n < len(b.queue) {
+		if b.BatchSize > 0 && n >= b.BatchSize {
+			break
+		}
+		if b.BatchBytes > 0 && n > 0 && size+len(b.queue[n]) > b.BatchBytes {
+			break
+		}
+		size += len(b.queue[n])
+		n++
+	}
+
+	batch := b.queue[:n:n]
+	b.queue = b.queue[n:]
+	b.queueBytes -= size
+	b.cond.Broadcast()
+	return batch
+}
+
+// send sends a batch, retrying with exponential backoff on
+// failure. The send mutex must be held.
+func (b *Batching) send(batch [][]byte) error {
+	attempt, err := b.sender(batch)
+	if err != nil {
+		return err
+	}
+
+	backoff := b.InitialBackoff
+	for i := 0; ; i++ {
+		wait, retry, err := attempt()
+		if err == nil {
+			return nil
+		}
+
+		b.mu.Lock()
+		b.stats.Errors++
+		b.mu.Unlock()
+
+		if !retry || i >= b.MaxRetries {
+			return err
+		}
+
+		if wait < 0 {
+			wait = backoff
+			backoff *= 2
+			if backoff > b.MaxBackoff {
+				backoff = b.MaxBackoff
+			}
+		}
+
+		// Give up on the batch if the sink is closed, so that
+		// Close doesn't block while the destination is down
+		select {
+		case <-time.After(wait):
+		case <-b.done:
+			return err
+		}
+
+		b.mu.Lock()
+		b.stats.Retries++
+		b.mu.Unlock()
+	}
+}
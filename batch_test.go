@@ -0,0 +1,113 @@
+package logger_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.elara.ws/logger"
+)
+
+// httpCollector is a stand-in for the HTTP endpoint of a log
+// collector that records the requests it receives. The first
+// requests fail with the given status, or 503 if it's not set.
+type httpCollector struct {
+	mu       sync.Mutex
+	failures int
+	status   int
+	header   http.Header
+	headers  []http.Header
+	bodies   []string
+	requests int
+}
+
+func (hc *httpCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	hc.requests++
+	if hc.failures > 0 {
+		hc.failures--
+		for k, v := range hc.header {
+			w.Header()[k] = v
+		}
+		if hc.status == 0 {
+			hc.status = http.StatusServiceUnavailable
+		}
+		w.WriteHeader(hc.status)
+		return
+	}
+
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body = zr
+	}
+	data, _ := io.ReadAll(body)
+	hc.headers = append(hc.headers, r.Header)
+	hc.bodies = append(hc.bodies, string(data))
+}
+
+// requestBodies returns the bodies of the successful requests
+func (hc *httpCollector) requestBodies() []string {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	return append([]string(nil), hc.bodies...)
+}
+
+// lines returns the lines of all the request bodies
+func (hc *httpCollector) lines() []string {
+	var out []string
+	for _, body := range hc.requestBodies() {
+		sc := bufio.NewScanner(strings.NewReader(body))
+		for sc.Scan() {
+			out = append(out, sc.Text())
+		}
+	}
+	return out
+}
+
+// serveCollector starts a test server for hc and returns its URL
+func serveCollector(t *testing.T, hc *httpCollector) string {
+	t.Helper()
+	srv := httptest.NewServer(hc)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// startBatchingTest makes a batching sink retry
+// quickly and closes it when the test ends
+func startBatchingTest(t *testing.T, sink io.Closer, b *logger.Batching) {
+	t.Helper()
+	b.InitialBackoff = time.Millisecond
+	t.Cleanup(func() { sink.Close() })
+}
+
+// assertClosedError closes a batching sink, sends an event to it
+// using send and checks that the error reaches the error handler
+func assertClosedError(t *testing.T, sink io.Closer, b *logger.Batching, send func()) {
+	t.Helper()
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The handler is set after closing, since the
+	// background goroutine may call it until then
+	var got error
+	b.ErrorHandler = func(err error) { got = err }
+	send()
+	if !errors.Is(got, logger.ErrWriterClosed) {
+		t.Errorf("got: %v, want: %v", got, logger.ErrWriterClosed)
+	}
+}
@@ -1,7 +1,7 @@
 package logger
 
 import (
-	"bufio"
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -24,6 +24,10 @@ type CLILogger struct {
 	Level    LogLevel
 	UseColor bool
 
+	ErrorHandling
+	Environment
+	Hooks
+
 	noPanic bool
 	noExit  bool
 
@@ -77,6 +81,13 @@ func (pl *CLILogger) SetLevel(l LogLevel) {
 	pl.Level = l
 }
 
+// Enabled reports whether events with the given level are logged.
+// If any hooks have been added, every level is enabled, since the
+// hooks may change the level of events.
+func (pl *CLILogger) Enabled(lvl LogLevel) bool {
+	return pl.Out != io.Discard && (lvl >= pl.Level || pl.hooked())
+}
+
 // Debug creates a new debug event with the given message
 func (pl *CLILogger) Debug(msg string) LogBuilder {
 	return newCLILogBuilder(pl, msg, LogLevelDebug)
@@ -150,17 +161,48 @@ func (pl *CLILogger) Panicf(format string, v ...any) LogBuilder {
 type CLILogBuilder struct {
 	l   *CLILogger
 	lvl LogLevel
-	out *bufio.Writer
+	out writer
+	pe  *PanicEvent
+	now func() time.Time
 }
 
 func newCLILogBuilder(pl *CLILogger, msg string, lvl LogLevel) LogBuilder {
-	if pl.Out == io.Discard || lvl < pl.Level {
+	return newCLILogBuilderClock(pl, msg, lvl, pl.Now)
+}
+
+// newCLILogBuilderClock creates a new builder for an
+// event whose time is returned by now
+func newCLILogBuilderClock(pl *CLILogger, msg string, lvl LogLevel, now func() time.Time) LogBuilder {
+	if !pl.Enabled(lvl) {
 		return NopLogBuilder{}
 	}
+	if pl.hooked() {
+		return pl.withHooks(lvl, msg, now, func(lvl LogLevel, msg string) LogBuilder {
+			// The level is checked after the hooks have run,
+			// since they may have changed it
+			if lvl < pl.Level {
+				return NopLogBuilder{}
+			}
+			return startCLILogBuilder(pl, msg, lvl, now)
+		})
+	}
+	return startCLILogBuilder(pl, msg, lvl, now)
+}
+
+// LogEvent logs a collected event, keeping its time
+func (pl *CLILogger) LogEvent(e *Event) {
+	now := func() time.Time { return e.Time }
+	e.Apply(newCLILogBuilderClock(pl, e.Message, e.Level, now)).Send()
+}
+
+// startCLILogBuilder creates a new builder and
+// writes the beginning of the event to it
+func startCLILogBuilder(pl *CLILogger, msg string, lvl LogLevel, now func() time.Time) LogBuilder {
 	lb := &CLILogBuilder{
 		l:   pl,
-		out: bufio.NewWriter(pl.Out),
+		out: writer{&bytes.Buffer{}, pl.Out},
 		lvl: lvl,
+		now: now,
 	}
 
 	switch lvl {
@@ -180,6 +222,9 @@ func newCLILogBuilder(pl *CLILogger, msg string, lvl LogLevel) LogBuilder {
 	lb.out.WriteByte(' ')
 
 	lb.writeColor(lb.l.MsgColor, msg)
+	if lvl == LogLevelPanic && !pl.noPanic {
+		return capturePanic(lb, msg, now, &lb.pe)
+	}
 	return lb
 }
 
@@ -275,7 +320,7 @@ func (plb *CLILogBuilder) Bytes(key string, b []byte) LogBuilder {
 // Timestamp adds the time formatted as RFC3339Nano
 // as a field to the output
 func (plb *CLILogBuilder) Timestamp() LogBuilder {
-	return plb.Str("timestamp", time.Now().Format(time.RFC3339Nano))
+	return plb.Str("timestamp", plb.now().Format(time.RFC3339Nano))
 }
 
 // Bool adds a bool as a field to the output
@@ -324,11 +369,11 @@ func (plb *CLILogBuilder) Err(err error) LogBuilder {
 // After calling send, do not use the event again.
 func (plb *CLILogBuilder) Send() {
 	plb.out.WriteByte('\n')
-	plb.out.Flush()
+	plb.l.flush(plb.out)
 	if plb.lvl == LogLevelFatal && !plb.l.noExit {
-		os.Exit(1)
+		plb.l.exit()
 	} else if plb.lvl == LogLevelPanic && !plb.l.noPanic {
-		panic("")
+		plb.l.doPanic(plb.pe)
 	}
 }
 
@@ -0,0 +1,264 @@
+package logger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ Logger = (*Deduper)(nil)
+
+// Deduper implements the Logger interface by suppressing
+// identical events sent within a window of time.
+//
+// Events are identical if they have the same level and message,
+// and the same values for the fields listed in Keys. The first
+// occurrence is logged immediately. When the window closes, or
+// when Sync is called, an event with the message
+// "message repeated N times" (or "message repeated once")
+// is logged if any duplicates were suppressed.
+//
+// Fatal and panic events are never suppressed.
+type Deduper struct {
+	Logger Logger
+	Window time.Duration
+	// Keys are the keys of the fields that have to be
+	// equal, in addition to the level and message,
+	// for events to be considered identical
+	Keys []string
+
+	mu      sync.Mutex
+	entries map[string]*dedupEntry
+}
+
+// dedupEntry keeps track of the duplicates
+// of an event within the current window
+type dedupEntry struct {
+	lvl    LogLevel
+	msg    string
+	fields []Field
+	count  int
+	timer  *time.Timer
+}
+
+// NewDeduper creates and returns a new Deduper wrapping l
+// that suppresses identical events within the given window
+func NewDeduper(l Logger, window time.Duration, keys ...string) *Deduper {
+	return &Deduper{
+		Logger:  l,
+		Window:  window,
+		Keys:    keys,
+		entries: map[string]*dedupEntry{},
+	}
+}
+
+// NoPanic prevents the logger from panicking on panic events
+func (d *Deduper) NoPanic() {
+	d.Logger.NoPanic()
+}
+
+// NoExit prevents the logger from exiting on fatal events
+func (d *Deduper) NoExit() {
+	d.Logger.NoExit()
+}
+
+// Now returns the current time using
+// the clock of the underlying logger
+func (d *Deduper) Now() time.Time {
+	return clockOf(d.Logger)()
+}
+
+// Enabled reports whether events with the given level are logged
+func (d *Deduper) Enabled(lvl LogLevel) bool {
+	return enabled(d.Logger, lvl)
+}
+
+// SetLevel sets the log level of the logger
+func (d *Deduper) SetLevel(l LogLevel) {
+	d.Logger.SetLevel(l)
+}
+
+// Debug creates a new debug event with the given message
+func (d *Deduper) Debug(msg string) LogBuilder {
+	return newDedupLogBuilder(d, msg, LogLevelDebug)
+}
+
+// Debugf creates a new debug event with the formatted message
+func (d *Deduper) Debugf(format string, v ...any) LogBuilder {
+	return newDedupLogBuilder(d, fmt.Sprintf(format, v...), LogLevelDebug)
+}
+
+// Info creates a new info event with the given message
+func (d *Deduper) Info(msg string) LogBuilder {
+	return newDedupLogBuilder(d, msg, LogLevelInfo)
+}
+
+// Infof creates a new info event with the formatted message
+func (d *Deduper) Infof(format string, v ...any) LogBuilder {
+	return newDedupLogBuilder(d, fmt.Sprintf(format, v...), LogLevelInfo)
+}
+
+// Warn creates a new warn event with the given message
+func (d *Deduper) Warn(msg string) LogBuilder {
+	return newDedupLogBuilder(d, msg, LogLevelWarn)
+}
+
+// Warnf creates a new warn event with the formatted message
+func (d *Deduper) Warnf(format string, v ...any) LogBuilder {
+	return newDedupLogBuilder(d, fmt.Sprintf(format, v...), LogLevelWarn)
+}
+
+// Error creates a new error event with the given message
+func (d *Deduper) Error(msg string) LogBuilder {
+	return newDedupLogBuilder(d, msg, LogLevelError)
+}
+
+// Errorf creates a new error event with the formatted message
+func (d *Deduper) Errorf(format string, v ...any) LogBuilder {
+	return newDedupLogBuilder(d, fmt.Sprintf(format, v...), LogLevelError)
+}
+
+// Fatal creates a new fatal event with the given message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (d *Deduper) Fatal(msg string) LogBuilder {
+	return newDedupLogBuilder(d, msg, LogLevelFatal)
+}
+
+// Fatalf creates a new fatal event with the formatted message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (d *Deduper) Fatalf(format string, v ...any) LogBuilder {
+	return newDedupLogBuilder(d, fmt.Sprintf(format, v...), LogLevelFatal)
+}
+
+// Panic creates a new panic event with the given message
+//
+// When sent, panic events will cause a panic
+func (d *Deduper) Panic(msg string) LogBuilder {
+	return newDedupLogBuilder(d, msg, LogLevelPanic)
+}
+
+// Panicf creates a new panic event with the formatted message
+//
+// When sent, panic events will cause a panic
+func (d *Deduper) Panicf(format string, v ...any) LogBuilder {
+	return newDedupLogBuilder(d, fmt.Sprintf(format, v...), LogLevelPanic)
+}
+
+func newDedupLogBuilder(d *Deduper, msg string, lvl LogLevel) LogBuilder {
+	if !d.Enabled(lvl) {
+		return NopLogBuilder{}
+	}
+	return newEventLogBuilder(lvl, msg, d.Now, d.send)
+}
+
+// send logs the event if it's the first occurrence
+// in the current window, or counts it otherwise
+func (d *Deduper) send(e *Event) {
+	if e.Level >= LogLevelFatal {
+		e.Replay(d.Logger)
+		return
+	}
+
+	key, fields := d.key(e)
+
+	d.mu.Lock()
+	if entry, ok := d.entries[key]; ok {
+		entry.count++
+		d.mu.Unlock()
+		return
+	}
+	entry := &dedupEntry{lvl: e.Level, msg: e.Message, fields: fields}
+	d.entries[key] = entry
+	entry.timer = time.AfterFunc(d.Window, func() { d.expire(key, entry) })
+	d.mu.Unlock()
+
+	e.Replay(d.Logger)
+}
+
+// key returns the string identifying duplicates of e,
+// and the fields of e whose keys are in Keys. Each part
+// is prefixed with its length, and each field with
+// whether it's present and its kind, so that different
+// events can't produce the same key.
+func (d *Deduper) key(e *Event) (string, []Field) {
+	var sb strings.Builder
+	sb.WriteByte(byte(e.Level))
+	writeDedupPart(&sb, e.Message)
+
+	var fields []Field
+	for _, key := range d.Keys {
+		found := false
+		for _, f := range e.Fields {
+			if f.Key == key && f.Kind != KindError {
+				sb.WriteByte(1)
+				sb.WriteByte(byte(f.Kind))
+				writeDedupPart(&sb, f.ValueString())
+				fields = append(fields, f)
+				found = true
+				break
+			}
+		}
+		if !found {
+			sb.WriteByte(0)
+		}
+	}
+	return sb.String(), fields
+}
+
+// writeDedupPart writes s to sb, prefixed with its length
+func writeDedupPart(sb *strings.Builder, s string) {
+	sb.WriteString(strconv.Itoa(len(s)))
+	sb.WriteByte(':')
+	sb.WriteString(s)
+}
+
+// expire ends the window of an entry,
+// logging a summary if required
+func (d *Deduper) expire(key string, entry *dedupEntry) {
+	d.mu.Lock()
+	if d.entries[key] != entry {
+		// The entry has already been removed by Sync
+		d.mu.Unlock()
+		return
+	}
+	delete(d.entries, key)
+	d.mu.Unlock()
+
+	d.summarize(entry)
+}
+
+// Sync ends the current window of all events,
+// logging summaries of any suppressed duplicates
+func (d *Deduper) Sync() {
+	d.mu.Lock()
+	entries := d.entries
+	d.entries = map[string]*dedupEntry{}
+	d.mu.Unlock()
+
+	for _, entry := range entries {
+		entry.timer.Stop()
+		d.summarize(entry)
+	}
+}
+
+// summarize logs a summary of the duplicates of
+// an entry, if any were suppressed
+func (d *Deduper) summarize(entry *dedupEntry) {
+	if entry.count == 0 {
+		return
+	}
+	msg := "message repeated once"
+	if entry.count > 1 {
+		msg = fmt.Sprintf("message repeated %d times", entry.count)
+	}
+	lb := logBuilder(d.Logger, entry.lvl, msg)
+	lb = lb.Str("repeated_msg", entry.msg)
+	for _, f := range entry.fields {
+		lb = f.Apply(lb)
+	}
+	lb.Send()
+}
@@ -0,0 +1,108 @@
+package logger_test
+
+import (
+	"testing"
+	"time"
+
+	"go.elara.ws/logger"
+	"go.elara.ws/logger/logtest"
+)
+
+func TestDeduper(t *testing.T) {
+	t.Run("sync", func(t *testing.T) {
+		obs := logtest.New()
+		d := logger.NewDeduper(obs, time.Hour)
+
+		for i := 0; i < 5; i++ {
+			d.Error("Connection failed").Int("attempt", i).Send()
+		}
+		d.Warn("Connection failed").Send()
+
+		if got, want := obs.Len(), 2; got != want {
+			t.Fatalf("got: %d events, want: %d", got, want)
+		}
+		obs.RequireLogged(t, logger.LogLevelError, "Connection failed", logtest.Int("attempt", 0))
+
+		d.Sync()
+		obs.RequireLogged(t, logger.LogLevelError, "message repeated 4 times", logtest.Str("repeated_msg", "Connection failed"))
+		if got, want := obs.Len(), 3; got != want {
+			t.Errorf("got: %d events, want: %d", got, want)
+		}
+
+		// The window is over, so the next event is logged
+		d.Error("Connection failed").Send()
+		if got, want := obs.Count(logger.LogLevelError, "Connection failed"), 2; got != want {
+			t.Errorf("got: %d events, want: %d", got, want)
+		}
+	})
+
+	t.Run("window", func(t *testing.T) {
+		obs := logtest.New()
+		d := logger.NewDeduper(obs, 20*time.Millisecond)
+
+		for i := 0; i < 3; i++ {
+			d.Info("Test").Send()
+		}
+
+		deadline := time.Now().Add(5 * time.Second)
+		for !obs.Logged(logger.LogLevelInfo, "message repeated 2 times") {
+			if time.Now().After(deadline) {
+				t.Fatal("summary wasn't logged when the window closed")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	})
+
+	t.Run("keys", func(t *testing.T) {
+		obs := logtest.New()
+		d := logger.NewDeduper(obs, time.Hour, "host")
+
+		d.Error("Unreachable").Str("host", "a").Send()
+		d.Error("Unreachable").Str("host", "b").Send()
+		d.Error("Unreachable").Str("host", "a").Send()
+		d.Sync()
+
+		if got, want := obs.Count(logger.LogLevelError, "Unreachable"), 2; got != want {
+			t.Errorf("got: %d events, want: %d", got, want)
+		}
+		obs.RequireLogged(t, logger.LogLevelError, "message repeated once", logtest.Str("host", "a"))
+		obs.AssertNotLogged(t, logger.LogLevelError, "message repeated once", logtest.Str("host", "b"))
+	})
+
+	t.Run("fatal", func(t *testing.T) {
+		obs := logtest.New()
+		d := logger.NewDeduper(obs, time.Hour)
+
+		d.Fatal("Test").Send()
+		d.Fatal("Test").Send()
+
+		if got, want := obs.Len(), 2; got != want {
+			t.Errorf("got: %d events, want: %d", got, want)
+		}
+	})
+
+	t.Run("missing-key", func(t *testing.T) {
+		obs := logtest.New()
+		d := logger.NewDeduper(obs, time.Hour, "host", "port")
+
+		// A missing key must not collide with an
+		// empty value, or with the next key's value
+		d.Error("Unreachable").Str("host", "").Send()
+		d.Error("Unreachable").Send()
+		d.Error("Unreachable").Str("port", "").Send()
+
+		if got, want := obs.Count(logger.LogLevelError, "Unreachable"), 3; got != want {
+			t.Errorf("got: %d events, want: %d", got, want)
+		}
+	})
+
+	t.Run("level", func(t *testing.T) {
+		obs := logtest.New()
+		obs.Level = logger.LogLevelWarn
+		d := logger.NewDeduper(obs, time.Hour)
+
+		if _, ok := d.Info("Test").(logger.NopLogBuilder); !ok {
+			t.Error("expected a disabled level to return NopLogBuilder")
+		}
+	})
+}
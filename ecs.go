@@ -0,0 +1,249 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var _ Logger = (*ECSLogger)(nil)
+
+// ECSVersion is the version of the Elastic Common Schema
+// that ECSLogger's output conforms to
+const ECSVersion = "8.11.0"
+
+// ECSLogger implements the Logger interface using
+// JSON documents conforming to the Elastic Common Schema.
+//
+// Field keys containing dots are nested, so a field
+// with the key "http.request.method" is written as
+// {"http":{"request":{"method":...}}}.
+type ECSLogger struct {
+	Out   io.Writer
+	Level LogLevel
+
+	ErrorHandling
+	Environment
+
+	noPanic bool
+	noExit  bool
+}
+
+// NewECS creates and returns a new ECSLogger
+func NewECS(out io.Writer) *ECSLogger {
+	return &ECSLogger{Out: out, Level: LogLevelInfo}
+}
+
+// NoPanic prevents the logger from panicking on panic events
+func (el *ECSLogger) NoPanic() {
+	el.noPanic = true
+}
+
+// NoExit prevents the logger from exiting on fatal events
+func (el *ECSLogger) NoExit() {
+	el.noExit = true
+}
+
+// SetLevel sets the log level of the logger
+func (el *ECSLogger) SetLevel(l LogLevel) {
+	el.Level = l
+}
+
+// Enabled reports whether events with the given level are logged
+func (el *ECSLogger) Enabled(lvl LogLevel) bool {
+	return el.Out != io.Discard && lvl >= el.Level
+}
+
+// Debug creates a new debug event with the given message
+func (el *ECSLogger) Debug(msg string) LogBuilder {
+	return newECSLogBuilder(el, msg, LogLevelDebug)
+}
+
+// Debugf creates a new debug event with the formatted message
+func (el *ECSLogger) Debugf(format string, v ...any) LogBuilder {
+	return newECSLogBuilder(el, fmt.Sprintf(format, v...), LogLevelDebug)
+}
+
+// Info creates a new info event with the given message
+func (el *ECSLogger) Info(msg string) LogBuilder {
+	return newECSLogBuilder(el, msg, LogLevelInfo)
+}
+
+// Infof creates a new info event with the formatted message
+func (el *ECSLogger) Infof(format string, v ...any) LogBuilder {
+	return newECSLogBuilder(el, fmt.Sprintf(format, v...), LogLevelInfo)
+}
+
+// Warn creates a new warn event with the given message
+func (el *ECSLogger) Warn(msg string) LogBuilder {
+	return newECSLogBuilder(el, msg, LogLevelWarn)
+}
+
+// Warnf creates a new warn event with the formatted message
+func (el *ECSLogger) Warnf(format string, v ...any) LogBuilder {
+	return newECSLogBuilder(el, fmt.Sprintf(format, v...), LogLevelWarn)
+}
+
+// Error creates a new error event with the given message
+func (el *ECSLogger) Error(msg string) LogBuilder {
+	return newECSLogBuilder(el, msg, LogLevelError)
+}
+
+// Errorf creates a new error event with the formatted message
+func (el *ECSLogger) Errorf(format string, v ...any) LogBuilder {
+	return newECSLogBuilder(el, fmt.Sprintf(format, v...), LogLevelError)
+}
+
+// Fatal creates a new fatal event with the given message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (el *ECSLogger) Fatal(msg string) LogBuilder {
+	return newECSLogBuilder(el, msg, LogLevelFatal)
+}
+
+// Fatalf creates a new fatal event with the formatted message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (el *ECSLogger) Fatalf(format string, v ...any) LogBuilder {
+	return newECSLogBuilder(el, fmt.Sprintf(format, v...), LogLevelFatal)
+}
+
+// Panic creates a new panic event with the given message
+//
+// When sent, panic events will cause a panic
+func (el *ECSLogger) Panic(msg string) LogBuilder {
+	return newECSLogBuilder(el, msg, LogLevelPanic)
+}
+
+// Panicf creates a new panic event with the formatted message
+//
+// When sent, panic events will cause a panic
+func (el *ECSLogger) Panicf(format string, v ...any) LogBuilder {
+	return newECSLogBuilder(el, fmt.Sprintf(format, v...), LogLevelPanic)
+}
+
+func newECSLogBuilder(el *ECSLogger, msg string, lvl LogLevel) LogBuilder {
+	if !el.Enabled(lvl) {
+		return NopLogBuilder{}
+	}
+	return newEventLogBuilder(lvl, msg, el.Now, el.send)
+}
+
+// LogEvent logs a collected event, keeping its time
+func (el *ECSLogger) LogEvent(e *Event) {
+	if el.Enabled(e.Level) {
+		el.send(e)
+	}
+}
+
+// send encodes the event as an ECS document
+// and writes it to the output
+func (el *ECSLogger) send(e *Event) {
+	root := &ecsNode{}
+	root.set("@timestamp", Field{Kind: KindTime, Value: e.Time})
+	root.set("log.level", Field{Kind: KindString, Value: e.Level.String()})
+	root.set("message", Field{Kind: KindString, Value: e.Message})
+	root.set("ecs.version", Field{Kind: KindString, Value: ECSVersion})
+
+	for _, f := range e.Fields {
+		switch {
+		case f.Kind == KindTime && f.Key == "timestamp":
+			root.set("@timestamp", f)
+		case f.Kind == KindError:
+			err, _ := f.Value.(error)
+			if err == nil {
+				continue
+			}
+			root.set("error.message", Field{Kind: KindString, Value: err.Error()})
+			root.set("error.type", Field{Kind: KindString, Value: fmt.Sprintf("%T", err)})
+			// Errors implementing fmt.Formatter, such as the ones from
+			// github.com/pkg/errors, may include a stack trace in %+v
+			if _, ok := err.(fmt.Formatter); ok {
+				if st := fmt.Sprintf("%+v", err); st != err.Error() {
+					root.set("error.stack_trace", Field{Kind: KindString, Value: st})
+				}
+			}
+		default:
+			root.set(f.Key, f)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	root.write(buf)
+	buf.WriteByte('\n')
+	el.write(el.Out, buf.Bytes())
+
+	if e.Level == LogLevelFatal && !el.noExit {
+		el.exit()
+	} else if e.Level == LogLevelPanic && !el.noPanic {
+		el.doPanic(NewPanicEvent(e))
+	}
+}
+
+// ecsNode is a JSON object whose keys
+// are kept in insertion order
+type ecsNode struct {
+	entries []ecsEntry
+}
+
+// ecsEntry is a single key in an ecsNode. Exactly one
+// of field and child is used.
+type ecsEntry struct {
+	key   string
+	field Field
+	child *ecsNode
+}
+
+// set sets the value at the given dotted path, creating
+// nested objects as required. If a value already exists
+// at the path, it's replaced.
+func (n *ecsNode) set(path string, f Field) {
+	key, rest, nested := strings.Cut(path, ".")
+	for i := range n.entries {
+		entry := &n.entries[i]
+		if entry.key != key {
+			continue
+		}
+		if !nested {
+			entry.field, entry.child = f, nil
+			return
+		}
+		if entry.child == nil {
+			entry.child = &ecsNode{}
+		}
+		entry.child.set(rest, f)
+		return
+	}
+
+	if !nested {
+		n.entries = append(n.entries, ecsEntry{key: key, field: f})
+		return
+	}
+	child := &ecsNode{}
+	child.set(rest, f)
+	n.entries = append(n.entries, ecsEntry{key: key, child: child})
+}
+
+// write writes the node to the buffer as a JSON object
+func (n *ecsNode) write(buf *bytes.Buffer) {
+	buf.WriteByte('{')
+	for i, entry := range n.entries {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeJSONString(buf, entry.key)
+		buf.WriteByte(':')
+		switch {
+		case entry.child != nil:
+			entry.child.write(buf)
+		case entry.field.Kind == KindTime:
+			t := entry.field.Value.(time.Time).UTC()
+			writeJSONString(buf, t.Format("2006-01-02T15:04:05.000Z07:00"))
+		default:
+			writeJSONValue(buf, entry.field)
+		}
+	}
+	buf.WriteByte('}')
+}
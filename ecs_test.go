@@ -0,0 +1,127 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.elara.ws/logger"
+)
+
+// stackErr is an error that includes a
+// stack trace when formatted using %+v
+type stackErr struct{}
+
+func (stackErr) Error() string { return "stack err" }
+
+func (se stackErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		fmt.Fprint(s, "stack err\nmain.main()\n\tmain.go:1")
+		return
+	}
+	fmt.Fprint(s, se.Error())
+}
+
+func TestECS(t *testing.T) {
+	t.Run("base", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		ecslog := logger.NewECS(out)
+		ecslog.Info("Test").Send()
+
+		var doc struct {
+			Timestamp time.Time `json:"@timestamp"`
+			Message   string    `json:"message"`
+			Log       struct {
+				Level string `json:"level"`
+			} `json:"log"`
+			ECS struct {
+				Version string `json:"version"`
+			} `json:"ecs"`
+		}
+		if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+			t.Fatal(err)
+		}
+
+		if doc.Message != "Test" {
+			t.Errorf("expected message Test, got %s", doc.Message)
+		}
+		if doc.Log.Level != "info" {
+			t.Errorf("expected level info, got %s", doc.Log.Level)
+		}
+		if doc.ECS.Version != logger.ECSVersion {
+			t.Errorf("expected ecs version %s, got %s", logger.ECSVersion, doc.ECS.Version)
+		}
+		if doc.Timestamp.IsZero() {
+			t.Error("expected non-zero timestamp")
+		}
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		ecslog := logger.NewECS(out)
+		ecslog.Info("Test").
+			Str("http.request.method", "GET").
+			Int("http.response.status_code", 200).
+			Str("service.name", "test").
+			Send()
+
+		var doc map[string]any
+		if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+			t.Fatal(err)
+		}
+
+		http := doc["http"].(map[string]any)
+		if got, want := http["request"].(map[string]any)["method"], "GET"; got != want {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+		if got, want := http["response"].(map[string]any)["status_code"], float64(200); got != want {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+		if got, want := doc["service"].(map[string]any)["name"], "test"; got != want {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		ecslog := logger.NewECS(out)
+		ecslog.Error("Test").Err(errors.New("err")).Send()
+
+		var doc struct {
+			Error struct {
+				Message    string `json:"message"`
+				StackTrace string `json:"stack_trace"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if doc.Error.Message != "err" {
+			t.Errorf("expected error message err, got %s", doc.Error.Message)
+		}
+		if doc.Error.StackTrace != "" {
+			t.Errorf("expected no stack trace, got %s", doc.Error.StackTrace)
+		}
+
+		out.Reset()
+		ecslog.Error("Test").Err(stackErr{}).Send()
+		if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := doc.Error.StackTrace, "stack err\nmain.main()\n\tmain.go:1"; got != want {
+			t.Errorf("got: %q, want: %q", got, want)
+		}
+	})
+
+	t.Run("level", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		ecslog := logger.NewECS(out)
+		ecslog.Debug("Test").Send()
+		if out.Len() != 0 {
+			t.Errorf("expected no output, got %s", out.String())
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"os"
+	"time"
+)
+
+// Environment contains the functions loggers use to get the
+// current time and to end the program on fatal and panic events.
+// Replacing them allows tests to freeze time and to check what
+// happens on fatal events without calling NoExit. It's embedded
+// in every logger.
+type Environment struct {
+	// Clock returns the current time.
+	// If it's nil, time.Now is used.
+	Clock func() time.Time
+
+	// ExitFunc is called with the exit code on fatal events,
+	// after the exit handlers have run. If it's nil,
+	// os.Exit is used.
+	ExitFunc func(code int)
+
+	// ExitCode points to the exit code used on fatal
+	// events. If it's nil, 1 is used.
+	ExitCode *int
+
+	// PanicFunc is called with the panic value on panic
+	// events. If it's nil, the builtin panic is used.
+	PanicFunc func(v any)
+}
+
+// Now returns the current time using the clock
+func (env *Environment) Now() time.Time {
+	if env.Clock != nil {
+		return env.Clock()
+	}
+	return time.Now()
+}
+
+// Clocked is implemented by loggers that have a clock. Loggers that
+// embed Environment implement it, and wrappers such as Sampler and
+// Redactor implement it using the clock of the logger they wrap, so
+// that setting the clock of the underlying logger is enough.
+type Clocked interface {
+	Now() time.Time
+}
+
+// clockOf returns the clock of l, or time.Now
+// if l doesn't implement Clocked
+func clockOf(l Logger) func() time.Time {
+	if c, ok := l.(Clocked); ok {
+		return c.Now
+	}
+	return time.Now
+}
+
+// exit runs the exit handlers and then ends
+// the program with the configured exit code
+func (env *Environment) exit() {
+	RunExitHandlers()
+
+	code := 1
+	if env.ExitCode != nil {
+		code = *env.ExitCode
+	}
+	if env.ExitFunc != nil {
+		env.ExitFunc(code)
+		return
+	}
+	os.Exit(code)
+}
+
+// doPanic panics with the given value
+func (env *Environment) doPanic(v any) {
+	if env.PanicFunc != nil {
+		env.PanicFunc(v)
+		return
+	}
+	panic(v)
+}
@@ -0,0 +1,116 @@
+package logger_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"go.elara.ws/logger"
+	"go.elara.ws/logger/logtest"
+)
+
+var frozenTime = time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+func frozenClock() time.Time {
+	return frozenTime
+}
+
+func TestEnvironment(t *testing.T) {
+	t.Run("pretty-clock", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		pl := logger.NewPretty(buf)
+		pl.Clock = frozenClock
+
+		pl.Info("Test").Str("a", "b").Timestamp().Send()
+
+		if got, want := buf.String(), `3:04PM INF Test a="b" timestamp="2024-01-02T15:04:05Z"`+"\n"; got != want {
+			t.Errorf("got: %q, want: %q", got, want)
+		}
+	})
+
+	t.Run("json-clock", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		jl := logger.NewJSON(buf)
+		jl.Clock = frozenClock
+
+		jl.Info("Test").Timestamp().Send()
+
+		if got, want := buf.String(), `{"msg":"Test","level":"info","timestamp":"2024-01-02T15:04:05Z"}`; got != want {
+			t.Errorf("got: %s, want: %s", got, want)
+		}
+	})
+
+	t.Run("event-clock", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		el := logger.NewECS(buf)
+		el.Clock = frozenClock
+
+		el.Info("Test").Send()
+
+		if got, want := buf.String(), `{"@timestamp":"2024-01-02T15:04:05.000Z",`; !strings.HasPrefix(got, want) {
+			t.Errorf("got: %s, want prefix: %s", got, want)
+		}
+	})
+
+	t.Run("wrapper-clock", func(t *testing.T) {
+		o := logtest.New()
+		o.Clock = frozenClock
+		r := logger.NewRoute(logger.NewRedactor(o), logger.LogLevelDebug, logger.LogLevelPanic)
+
+		r.Info("Test").Send()
+
+		if got := o.Events()[0].Time; !got.Equal(frozenTime) {
+			t.Errorf("got: %s, want: %s", got, frozenTime)
+		}
+	})
+
+	t.Run("exit-code-zero", func(t *testing.T) {
+		code, exitCode := -1, 0
+		jl := logger.NewJSON(&bytes.Buffer{})
+		jl.ExitFunc = func(c int) { code = c }
+		jl.ExitCode = &exitCode
+
+		jl.Fatal("Test").Send()
+
+		if got, want := code, 0; got != want {
+			t.Errorf("got: exit code %d, want: %d", got, want)
+		}
+	})
+
+	t.Run("exit", func(t *testing.T) {
+		code := -1
+		jl := logger.NewJSON(&bytes.Buffer{})
+		jl.ExitFunc = func(c int) { code = c }
+
+		jl.Fatal("Test").Send()
+
+		if got, want := code, 1; got != want {
+			t.Errorf("got: exit code %d, want: %d", got, want)
+		}
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		panicked := false
+		pl := logger.NewPretty(&bytes.Buffer{})
+		pl.PanicFunc = func(v any) { panicked = true }
+
+		pl.Panic("Test").Send()
+
+		if !panicked {
+			t.Error("expected panic function to be called")
+		}
+	})
+
+	t.Run("multi", func(t *testing.T) {
+		code := -1
+		ml := logger.NewMulti(logger.NewJSON(&bytes.Buffer{}), logger.NewCLI(&bytes.Buffer{}))
+		ml.ExitFunc = func(c int) { code = c }
+
+		ml.Fatal("Test").Send()
+
+		if got, want := code, 1; got != want {
+			t.Errorf("got: exit code %d, want: %d", got, want)
+		}
+	})
+}
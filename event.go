@@ -0,0 +1,310 @@
+package logger
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+var _ LogBuilder = (*EventLogBuilder)(nil)
+
+// FieldKind represents the type of a field's value
+type FieldKind uint8
+
+// Field kinds
+const (
+	// KindInt is used for all signed integers. The value is an int64.
+	KindInt FieldKind = iota
+	// KindUint is used for all unsigned integers. The value is a uint64.
+	KindUint
+	// KindFloat32 is used for float32 values
+	KindFloat32
+	// KindFloat64 is used for float64 values
+	KindFloat64
+	// KindBool is used for bool values
+	KindBool
+	// KindString is used for strings and fmt.Stringer values.
+	// The value is a string.
+	KindString
+	// KindBytes is used for []byte values
+	KindBytes
+	// KindTime is used for timestamps. The value is a time.Time.
+	KindTime
+	// KindAny is used for values added using Any
+	KindAny
+	// KindError is used for errors. The value is an error.
+	KindError
+)
+
+// Field represents a single field of an event
+type Field struct {
+	Key   string
+	Kind  FieldKind
+	Value any
+}
+
+// Apply adds the field to lb and returns the result
+func (f Field) Apply(lb LogBuilder) LogBuilder {
+	switch f.Kind {
+	case KindInt:
+		return lb.Int64(f.Key, f.Value.(int64))
+	case KindUint:
+		return lb.Uint64(f.Key, f.Value.(uint64))
+	case KindFloat32:
+		return lb.Float32(f.Key, f.Value.(float32))
+	case KindFloat64:
+		return lb.Float64(f.Key, f.Value.(float64))
+	case KindBool:
+		return lb.Bool(f.Key, f.Value.(bool))
+	case KindString:
+		return lb.Str(f.Key, f.Value.(string))
+	case KindBytes:
+		return lb.Bytes(f.Key, f.Value.([]byte))
+	case KindTime:
+		return lb.Str(f.Key, f.Value.(time.Time).Format(time.RFC3339Nano))
+	case KindError:
+		err, _ := f.Value.(error)
+		return lb.Err(err)
+	default:
+		return lb.Any(f.Key, f.Value)
+	}
+}
+
+// ValueString returns the field's value formatted as a string.
+// Bytes are base64-encoded, and values added using Any
+// are marshaled as JSON.
+func (f Field) ValueString() string {
+	switch f.Kind {
+	case KindInt:
+		return strconv.FormatInt(f.Value.(int64), 10)
+	case KindUint:
+		return strconv.FormatUint(f.Value.(uint64), 10)
+	case KindFloat32:
+		return strconv.FormatFloat(float64(f.Value.(float32)), 'f', -1, 32)
+	case KindFloat64:
+		return strconv.FormatFloat(f.Value.(float64), 'f', -1, 64)
+	case KindBool:
+		return strconv.FormatBool(f.Value.(bool))
+	case KindString:
+		return f.Value.(string)
+	case KindBytes:
+		return base64.StdEncoding.EncodeToString(f.Value.([]byte))
+	case KindTime:
+		return f.Value.(time.Time).Format(time.RFC3339Nano)
+	case KindError:
+		return f.Value.(error).Error()
+	default:
+		data, err := json.Marshal(f.Value)
+		if err != nil {
+			return fmt.Sprint(f.Value)
+		}
+		return string(data)
+	}
+}
+
+// Event represents a log event whose fields have been
+// collected in memory rather than written directly
+// to an output.
+type Event struct {
+	Level   LogLevel
+	Message string
+	Time    time.Time
+	Fields  []Field
+}
+
+// Apply adds all the event's fields to lb, in order,
+// and returns the result
+func (e *Event) Apply(lb LogBuilder) LogBuilder {
+	for _, f := range e.Fields {
+		lb = f.Apply(lb)
+	}
+	return lb
+}
+
+// EventLogger is implemented by loggers that can log an
+// event that has already been collected, keeping its
+// original time, such as loggers built on Event.
+type EventLogger interface {
+	// LogEvent logs e if its level is enabled
+	LogEvent(e *Event)
+}
+
+// Replay logs e to l. If l implements EventLogger, the event is
+// logged as is, keeping its original time. Otherwise, a new event
+// with the same level, message and fields is created, which gets
+// its time from l.
+func (e *Event) Replay(l Logger) {
+	if el, ok := l.(EventLogger); ok {
+		el.LogEvent(e)
+		return
+	}
+	e.Apply(logBuilder(l, e.Level, e.Message)).Send()
+}
+
+// EventLogBuilder implements the LogBuilder interface
+// by collecting fields into an Event. When sent, the
+// event is passed to a function that handles it.
+type EventLogBuilder struct {
+	Event *Event
+	send  func(*Event)
+	now   func() time.Time
+}
+
+// NewEventLogBuilder creates and returns a new EventLogBuilder
+// that calls send with the collected event when sent.
+func NewEventLogBuilder(lvl LogLevel, msg string, send func(*Event)) *EventLogBuilder {
+	return newEventLogBuilder(lvl, msg, time.Now, send)
+}
+
+// NewEventLogBuilderClock is like NewEventLogBuilder,
+// but gets the current time from the given clock
+func NewEventLogBuilderClock(lvl LogLevel, msg string, now func() time.Time, send func(*Event)) *EventLogBuilder {
+	return newEventLogBuilder(lvl, msg, now, send)
+}
+
+// newEventLogBuilder creates and returns a new EventLogBuilder
+// that gets the current time from the given clock
+func newEventLogBuilder(lvl LogLevel, msg string, now func() time.Time, send func(*Event)) *EventLogBuilder {
+	return &EventLogBuilder{
+		Event: &Event{
+			Level:   lvl,
+			Message: msg,
+			Time:    now(),
+		},
+		send: send,
+		now:  now,
+	}
+}
+
+// add appends a field to the event
+func (elb *EventLogBuilder) add(key string, kind FieldKind, val any) LogBuilder {
+	elb.Event.Fields = append(elb.Event.Fields, Field{Key: key, Kind: kind, Value: val})
+	return elb
+}
+
+// Int adds an int field to the output
+func (elb *EventLogBuilder) Int(key string, val int) LogBuilder {
+	return elb.add(key, KindInt, int64(val))
+}
+
+// Int64 adds an int64 field to the output
+func (elb *EventLogBuilder) Int64(key string, val int64) LogBuilder {
+	return elb.add(key, KindInt, val)
+}
+
+// Int32 adds an int32 field to the output
+func (elb *EventLogBuilder) Int32(key string, val int32) LogBuilder {
+	return elb.add(key, KindInt, int64(val))
+}
+
+// Int16 adds an int16 field to the output
+func (elb *EventLogBuilder) Int16(key string, val int16) LogBuilder {
+	return elb.add(key, KindInt, int64(val))
+}
+
+// Int8 adds an int8 field to the output
+func (elb *EventLogBuilder) Int8(key string, val int8) LogBuilder {
+	return elb.add(key, KindInt, int64(val))
+}
+
+// Uint adds a uint field to the output
+func (elb *EventLogBuilder) Uint(key string, val uint) LogBuilder {
+	return elb.add(key, KindUint, uint64(val))
+}
+
+// Uint64 adds a uint64 field to the output
+func (elb *EventLogBuilder) Uint64(key string, val uint64) LogBuilder {
+	return elb.add(key, KindUint, val)
+}
+
+// Uint32 adds a uint32 field to the output
+func (elb *EventLogBuilder) Uint32(key string, val uint32) LogBuilder {
+	return elb.add(key, KindUint, uint64(val))
+}
+
+// Uint16 adds a uint16 field to the output
+func (elb *EventLogBuilder) Uint16(key string, val uint16) LogBuilder {
+	return elb.add(key, KindUint, uint64(val))
+}
+
+// Uint8 adds a uint8 field to the output
+func (elb *EventLogBuilder) Uint8(key string, val uint8) LogBuilder {
+	return elb.add(key, KindUint, uint64(val))
+}
+
+// Float64 adds a float64 field to the output
+func (elb *EventLogBuilder) Float64(key string, val float64) LogBuilder {
+	return elb.add(key, KindFloat64, val)
+}
+
+// Float32 adds a float32 field to the output
+func (elb *EventLogBuilder) Float32(key string, val float32) LogBuilder {
+	return elb.add(key, KindFloat32, val)
+}
+
+// Stringer calls the String method of an fmt.Stringer
+// and adds the resulting string as a field to the output
+func (elb *EventLogBuilder) Stringer(key string, s fmt.Stringer) LogBuilder {
+	return elb.add(key, KindString, s.String())
+}
+
+// Bytes adds []byte as a field to the output
+func (elb *EventLogBuilder) Bytes(key string, b []byte) LogBuilder {
+	return elb.add(key, KindBytes, b)
+}
+
+// Timestamp adds the current time as a field
+// to the output using the key "timestamp"
+func (elb *EventLogBuilder) Timestamp() LogBuilder {
+	return elb.add("timestamp", KindTime, elb.now())
+}
+
+// Bool adds a bool as a field to the output
+func (elb *EventLogBuilder) Bool(key string, val bool) LogBuilder {
+	return elb.add(key, KindBool, val)
+}
+
+// Str adds a string as a field to the output
+func (elb *EventLogBuilder) Str(key, val string) LogBuilder {
+	return elb.add(key, KindString, val)
+}
+
+// Any adds a value of any type as a field to the output
+func (elb *EventLogBuilder) Any(key string, val any) LogBuilder {
+	return elb.add(key, KindAny, val)
+}
+
+// Err adds an error as a field to the output
+// using the key "error"
+func (elb *EventLogBuilder) Err(err error) LogBuilder {
+	return elb.add("error", KindError, err)
+}
+
+// Send passes the collected event to the
+// builder's send function.
+//
+// After calling send, do not use the event again.
+func (elb *EventLogBuilder) Send() {
+	elb.send(elb.Event)
+}
+
+// logBuilder returns a builder for a new event
+// with the given level and message from l
+func logBuilder(l Logger, lvl LogLevel, msg string) LogBuilder {
+	switch lvl {
+	case LogLevelDebug:
+		return l.Debug(msg)
+	case LogLevelInfo:
+		return l.Info(msg)
+	case LogLevelWarn:
+		return l.Warn(msg)
+	case LogLevelError:
+		return l.Error(msg)
+	case LogLevelFatal:
+		return l.Fatal(msg)
+	default:
+		return l.Panic(msg)
+	}
+}
@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	exitMu       sync.Mutex
+	exitHandlers []*exitHandler
+	exitTimeout  = 5 * time.Second
+)
+
+// exitHandler wraps a registered function, so that
+// it can be found again when it's unregistered
+type exitHandler struct {
+	fn func()
+}
+
+// RegisterExitHandler adds a function that's called before a fatal
+// event ends the program, such as one that closes database connections
+// or flushes metrics. Handlers are called in the order they were
+// registered.
+//
+// The returned function unregisters the handler. It's safe to
+// call more than once.
+func RegisterExitHandler(fn func()) (unregister func()) {
+	h := &exitHandler{fn: fn}
+
+	exitMu.Lock()
+	defer exitMu.Unlock()
+	exitHandlers = append(exitHandlers, h)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { unregisterExitHandler(h) })
+	}
+}
+
+// unregisterExitHandler removes h from the exit handlers
+func unregisterExitHandler(h *exitHandler) {
+	exitMu.Lock()
+	defer exitMu.Unlock()
+	for i, eh := range exitHandlers {
+		if eh == h {
+			exitHandlers = append(exitHandlers[:i:i], exitHandlers[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetExitTimeout sets the maximum amount of time the exit handlers
+// may run for before the program exits anyway. The default is
+// five seconds.
+func SetExitTimeout(d time.Duration) {
+	exitMu.Lock()
+	defer exitMu.Unlock()
+	exitTimeout = d
+}
+
+// RunExitHandlers calls the registered exit handlers in order,
+// waiting at most for the exit timeout. Panics in handlers are
+// recovered, so that the remaining handlers still run.
+//
+// Loggers call this on fatal events, but it may also be called
+// before exiting for other reasons.
+func RunExitHandlers() {
+	exitMu.Lock()
+	handlers := make([]*exitHandler, len(exitHandlers))
+	copy(handlers, exitHandlers)
+	timeout := exitTimeout
+	exitMu.Unlock()
+
+	if len(handlers) == 0 {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, h := range handlers {
+			runExitHandler(h.fn)
+		}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+	}
+}
+
+// runExitHandler calls fn, recovering from any panics
+func runExitHandler(fn func()) {
+	defer func() { recover() }()
+	fn()
+}
@@ -0,0 +1,112 @@
+package logger_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"go.elara.ws/logger"
+)
+
+// exitRecorder records calls to exit handlers
+type exitRecorder struct {
+	mu    sync.Mutex
+	sleep time.Duration
+	calls []int
+}
+
+// registerExitRecorder registers three exit handlers that record
+// their calls, and unregisters them when the test ends. The second
+// handler sleeps for the given duration and then panics.
+func registerExitRecorder(t *testing.T, sleep time.Duration) *exitRecorder {
+	er := &exitRecorder{sleep: sleep}
+	for i := 1; i <= 3; i++ {
+		t.Cleanup(logger.RegisterExitHandler(er.handler(i)))
+	}
+	return er
+}
+
+func (er *exitRecorder) handler(n int) func() {
+	return func() {
+		er.mu.Lock()
+		er.calls = append(er.calls, n)
+		er.mu.Unlock()
+
+		if n == 2 {
+			time.Sleep(er.sleep)
+			panic("handlers must be recovered")
+		}
+	}
+}
+
+func (er *exitRecorder) recorded() []int {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	return append([]int(nil), er.calls...)
+}
+
+func TestExitHandlers(t *testing.T) {
+	t.Run("order", func(t *testing.T) {
+		code := 0
+		jl := logger.NewJSON(&bytes.Buffer{})
+		jl.ExitFunc = func(c int) { code = c }
+		exitCode := 3
+		jl.ExitCode = &exitCode
+
+		er := registerExitRecorder(t, 0)
+		jl.Fatal("Test").Send()
+		calls := er.recorded()
+
+		if got, want := code, 3; got != want {
+			t.Errorf("got: exit code %d, want: %d", got, want)
+		}
+		if got, want := len(calls), 3; got != want {
+			t.Fatalf("got: %d calls, want: %d", got, want)
+		}
+		for i, n := range calls {
+			if n != i+1 {
+				t.Errorf("unexpected handler order: %v", calls)
+			}
+		}
+	})
+
+	t.Run("multi", func(t *testing.T) {
+		ml := logger.NewMulti(logger.NewJSON(&bytes.Buffer{}), logger.NewPretty(&bytes.Buffer{}))
+		ml.ExitFunc = func(int) {}
+
+		er := registerExitRecorder(t, 0)
+		ml.Fatal("Test").Send()
+		calls := er.recorded()
+
+		if got, want := len(calls), 3; got != want {
+			t.Errorf("got: %d calls, want: %d", got, want)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		logger.SetExitTimeout(10 * time.Millisecond)
+		defer logger.SetExitTimeout(5 * time.Second)
+
+		registerExitRecorder(t, time.Second)
+		start := time.Now()
+		logger.RunExitHandlers()
+		elapsed := time.Since(start)
+
+		if elapsed > 500*time.Millisecond {
+			t.Errorf("expected exit handlers to time out, took %s", elapsed)
+		}
+	})
+
+	t.Run("unregister", func(t *testing.T) {
+		er := &exitRecorder{}
+		unregister := logger.RegisterExitHandler(er.handler(1))
+		unregister()
+		unregister()
+
+		logger.RunExitHandlers()
+		if calls := er.recorded(); len(calls) != 0 {
+			t.Errorf("unregistered handler was called: %v", calls)
+		}
+	})
+}
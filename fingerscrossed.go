@@ -0,0 +1,221 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+var _ Logger = (*FingersCrossedLogger)(nil)
+
+// FingersCrossedLogger implements the Logger interface by buffering
+// events below a trigger level, such as debug events for a single
+// request. When an event at or above the trigger level is logged,
+// the buffered events are flushed to the underlying logger, followed
+// by the event itself, and any further events in the same scope are
+// passed through. If the trigger level is never reached, the buffer
+// is discarded when the scope ends.
+//
+// The underlying logger's level has to be low enough for the
+// buffered events to be logged when they're flushed.
+type FingersCrossedLogger struct {
+	Logger Logger
+	// Trigger is the level at which the buffer is flushed
+	Trigger LogLevel
+	// BufferSize is the maximum amount of buffered events.
+	// When the buffer is full, the oldest event is dropped.
+	BufferSize int
+
+	mu        sync.Mutex
+	buf       []*Event
+	triggered bool
+}
+
+// NewFingersCrossed creates and returns a new FingersCrossedLogger
+// wrapping l that buffers up to size events below the trigger level
+func NewFingersCrossed(l Logger, trigger LogLevel, size int) *FingersCrossedLogger {
+	return &FingersCrossedLogger{Logger: l, Trigger: trigger, BufferSize: size}
+}
+
+// Scope returns a new FingersCrossedLogger with the same
+// settings and its own empty buffer, such as for a single
+// request. End should be called when the scope ends.
+func (fcl *FingersCrossedLogger) Scope() *FingersCrossedLogger {
+	return NewFingersCrossed(fcl.Logger, fcl.Trigger, fcl.BufferSize)
+}
+
+// End discards any buffered events
+func (fcl *FingersCrossedLogger) End() {
+	fcl.mu.Lock()
+	defer fcl.mu.Unlock()
+	fcl.buf = nil
+}
+
+// Flush sends all buffered events to the underlying logger,
+// as if the trigger level had been reached
+func (fcl *FingersCrossedLogger) Flush() {
+	fcl.mu.Lock()
+	fcl.triggered = true
+	buf := fcl.buf
+	fcl.buf = nil
+	fcl.mu.Unlock()
+
+	// Events are replayed so that they keep the time at
+	// which they were buffered if the logger implements
+	// EventLogger, as the builtin loggers do. Otherwise,
+	// they get the time at which they're flushed.
+	for _, e := range buf {
+		e.Replay(fcl.Logger)
+	}
+}
+
+// Triggered reports whether the buffer has been flushed
+func (fcl *FingersCrossedLogger) Triggered() bool {
+	fcl.mu.Lock()
+	defer fcl.mu.Unlock()
+	return fcl.triggered
+}
+
+// NoPanic prevents the logger from panicking on panic events
+func (fcl *FingersCrossedLogger) NoPanic() {
+	fcl.Logger.NoPanic()
+}
+
+// NoExit prevents the logger from exiting on fatal events
+func (fcl *FingersCrossedLogger) NoExit() {
+	fcl.Logger.NoExit()
+}
+
+// Now returns the current time using
+// the clock of the underlying logger
+func (fcl *FingersCrossedLogger) Now() time.Time {
+	return clockOf(fcl.Logger)()
+}
+
+// Enabled reports whether events with the given level are logged
+// by the underlying logger, which is also required for them
+// to be buffered
+func (fcl *FingersCrossedLogger) Enabled(lvl LogLevel) bool {
+	return enabled(fcl.Logger, lvl)
+}
+
+// SetLevel sets the log level of the logger
+func (fcl *FingersCrossedLogger) SetLevel(l LogLevel) {
+	fcl.Logger.SetLevel(l)
+}
+
+// Debug creates a new debug event with the given message
+func (fcl *FingersCrossedLogger) Debug(msg string) LogBuilder {
+	return newFingersCrossedLogBuilder(fcl, msg, LogLevelDebug)
+}
+
+// Debugf creates a new debug event with the formatted message
+func (fcl *FingersCrossedLogger) Debugf(format string, v ...any) LogBuilder {
+	return newFingersCrossedLogBuilder(fcl, fmt.Sprintf(format, v...), LogLevelDebug)
+}
+
+// Info creates a new info event with the given message
+func (fcl *FingersCrossedLogger) Info(msg string) LogBuilder {
+	return newFingersCrossedLogBuilder(fcl, msg, LogLevelInfo)
+}
+
+// Infof creates a new info event with the formatted message
+func (fcl *FingersCrossedLogger) Infof(format string, v ...any) LogBuilder {
+	return newFingersCrossedLogBuilder(fcl, fmt.Sprintf(format, v...), LogLevelInfo)
+}
+
+// Warn creates a new warn event with the given message
+func (fcl *FingersCrossedLogger) Warn(msg string) LogBuilder {
+	return newFingersCrossedLogBuilder(fcl, msg, LogLevelWarn)
+}
+
+// Warnf creates a new warn event with the formatted message
+func (fcl *FingersCrossedLogger) Warnf(format string, v ...any) LogBuilder {
+	return newFingersCrossedLogBuilder(fcl, fmt.Sprintf(format, v...), LogLevelWarn)
+}
+
+// Error creates a new error event with the given message
+func (fcl *FingersCrossedLogger) Error(msg string) LogBuilder {
+	return newFingersCrossedLogBuilder(fcl, msg, LogLevelError)
+}
+
+// Errorf creates a new error event with the formatted message
+func (fcl *FingersCrossedLogger) Errorf(format string, v ...any) LogBuilder {
+	return newFingersCrossedLogBuilder(fcl, fmt.Sprintf(format, v...), LogLevelError)
+}
+
+// Fatal creates a new fatal event with the given message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (fcl *FingersCrossedLogger) Fatal(msg string) LogBuilder {
+	return newFingersCrossedLogBuilder(fcl, msg, LogLevelFatal)
+}
+
+// Fatalf creates a new fatal event with the formatted message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (fcl *FingersCrossedLogger) Fatalf(format string, v ...any) LogBuilder {
+	return newFingersCrossedLogBuilder(fcl, fmt.Sprintf(format, v...), LogLevelFatal)
+}
+
+// Panic creates a new panic event with the given message
+//
+// When sent, panic events will cause a panic
+func (fcl *FingersCrossedLogger) Panic(msg string) LogBuilder {
+	return newFingersCrossedLogBuilder(fcl, msg, LogLevelPanic)
+}
+
+// Panicf creates a new panic event with the formatted message
+//
+// When sent, panic events will cause a panic
+func (fcl *FingersCrossedLogger) Panicf(format string, v ...any) LogBuilder {
+	return newFingersCrossedLogBuilder(fcl, fmt.Sprintf(format, v...), LogLevelPanic)
+}
+
+func newFingersCrossedLogBuilder(fcl *FingersCrossedLogger, msg string, lvl LogLevel) LogBuilder {
+	if !fcl.Enabled(lvl) {
+		return NopLogBuilder{}
+	}
+	if lvl >= fcl.Trigger {
+		// The buffer is flushed when the triggering event is
+		// sent rather than when it's created, so that events
+		// logged in between are flushed too, and the event
+		// is only logged once its fields have been added
+		return newEventLogBuilder(lvl, msg, fcl.Now, fcl.trigger)
+	}
+
+	fcl.mu.Lock()
+	triggered := fcl.triggered
+	fcl.mu.Unlock()
+	if triggered {
+		return logBuilder(fcl.Logger, lvl, msg)
+	}
+	return newEventLogBuilder(lvl, msg, fcl.Now, fcl.buffer)
+}
+
+// trigger flushes the buffer and then logs the triggering event
+func (fcl *FingersCrossedLogger) trigger(e *Event) {
+	fcl.Flush()
+	e.Replay(fcl.Logger)
+}
+
+// buffer adds an event to the buffer, dropping the
+// oldest one if it's full. If the buffer has been
+// flushed in the meantime, the event is logged.
+func (fcl *FingersCrossedLogger) buffer(e *Event) {
+	fcl.mu.Lock()
+	if fcl.triggered {
+		fcl.mu.Unlock()
+		e.Replay(fcl.Logger)
+		return
+	}
+	defer fcl.mu.Unlock()
+
+	if fcl.BufferSize <= 0 {
+		return
+	}
+	if len(fcl.buf) >= fcl.BufferSize {
+		fcl.buf = fcl.buf[1:]
+	}
+	fcl.buf = append(fcl.buf, e)
+}
@@ -0,0 +1,151 @@
+package logger_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"go.elara.ws/logger"
+	"go.elara.ws/logger/logtest"
+)
+
+func TestFingersCrossed(t *testing.T) {
+	t.Run("trigger", func(t *testing.T) {
+		obs := logtest.New()
+		fcl := logger.NewFingersCrossed(obs, logger.LogLevelError, 10)
+
+		req := fcl.Scope()
+		req.Debug("Parsing request").Str("path", "/").Send()
+		req.Info("Querying database").Send()
+		if got, want := obs.Len(), 0; got != want {
+			t.Fatalf("got: %d events before trigger, want: %d", got, want)
+		}
+
+		req.Error("Query failed").Send()
+		req.Debug("Cleaning up").Send()
+		req.End()
+
+		events := obs.Events()
+		if got, want := len(events), 4; got != want {
+			t.Fatalf("got: %d events, want: %d", got, want)
+		}
+		for i, msg := range []string{"Parsing request", "Querying database", "Query failed", "Cleaning up"} {
+			if got := events[i].Message; got != msg {
+				t.Errorf("got: %s at %d, want: %s", got, i, msg)
+			}
+		}
+		obs.RequireLogged(t, logger.LogLevelDebug, "Parsing request", logtest.Str("path", "/"))
+	})
+
+	t.Run("discard", func(t *testing.T) {
+		obs := logtest.New()
+		fcl := logger.NewFingersCrossed(obs, logger.LogLevelError, 10)
+
+		req := fcl.Scope()
+		req.Debug("Parsing request").Send()
+		req.Warn("Slow query").Send()
+		req.End()
+
+		// Other scopes don't share the buffer
+		other := fcl.Scope()
+		other.Error("Failed").Send()
+
+		if got, want := obs.Len(), 1; got != want {
+			t.Errorf("got: %d events, want: %d", got, want)
+		}
+		if req.Triggered() || !other.Triggered() {
+			t.Error("unexpected triggered state")
+		}
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		obs := logtest.New()
+		fcl := logger.NewFingersCrossed(obs, logger.LogLevelWarn, 2)
+
+		fcl.Debugf("Event %d", 1).Send()
+		fcl.Debugf("Event %d", 2).Send()
+		fcl.Debugf("Event %d", 3).Send()
+		fcl.Flush()
+
+		if got, want := obs.Len(), 2; got != want {
+			t.Fatalf("got: %d events, want: %d", got, want)
+		}
+		if got, want := obs.Events()[0].Message, "Event 2"; got != want {
+			t.Errorf("got: %s, want: %s", got, want)
+		}
+	})
+
+	t.Run("flush-on-send", func(t *testing.T) {
+		obs := logtest.New()
+		fcl := logger.NewFingersCrossed(obs, logger.LogLevelError, 10)
+
+		lb := fcl.Error("Query failed")
+		fcl.Debug("Retrying").Send()
+		if got, want := obs.Len(), 0; got != want {
+			t.Fatalf("got: %d events before send, want: %d", got, want)
+		}
+		lb.Str("table", "users").Send()
+
+		events := obs.Events()
+		if got, want := len(events), 2; got != want {
+			t.Fatalf("got: %d events, want: %d", got, want)
+		}
+		if events[0].Message != "Retrying" || events[1].Message != "Query failed" {
+			t.Errorf("unexpected event order: %v", events)
+		}
+		obs.AssertLogged(t, logger.LogLevelError, "Query failed", logtest.Str("table", "users"))
+	})
+
+	t.Run("replay-time", func(t *testing.T) {
+		now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+		obs := logtest.New()
+		obs.Clock = func() time.Time { return now }
+		fcl := logger.NewFingersCrossed(obs, logger.LogLevelError, 10)
+
+		var want []time.Time
+		for _, msg := range []string{"First", "Second"} {
+			fcl.Debug(msg).Send()
+			want = append(want, now)
+			now = now.Add(time.Second)
+		}
+		lb := fcl.Error("Failed")
+		want = append(want, now)
+		now = now.Add(time.Second)
+		lb.Send()
+
+		events := obs.Events()
+		if got := len(events); got != len(want) {
+			t.Fatalf("got: %d events, want: %d", got, len(want))
+		}
+		for i, e := range events {
+			if !e.Time.Equal(want[i]) {
+				t.Errorf("got: %s for %s, want: %s", e.Time, e.Message, want[i])
+			}
+		}
+	})
+
+	t.Run("replay-time-pretty", func(t *testing.T) {
+		now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+		buf := &bytes.Buffer{}
+		pl := logger.NewPretty(buf)
+		pl.Clock = func() time.Time { return now }
+		pl.TimeFormat = time.RFC3339
+		pl.SetLevel(logger.LogLevelDebug)
+		fcl := logger.NewFingersCrossed(pl, logger.LogLevelError, 10)
+
+		fcl.Debug("First").Send()
+		now = now.Add(time.Minute)
+		fcl.Error("Failed").Send()
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("got: %d lines, want: 2: %s", len(lines), buf.String())
+		}
+		for i, want := range []string{"2024-01-02T15:04:05Z", "2024-01-02T15:05:05Z"} {
+			if !strings.Contains(lines[i], want) {
+				t.Errorf("got: %s, want time: %s", lines[i], want)
+			}
+		}
+	})
+}
@@ -0,0 +1,319 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+var _ Logger = (*FluentLogger)(nil)
+
+// ErrFluentAck is returned when Fluentd responds
+// with an ack for the wrong chunk
+var ErrFluentAck = errors.New("fluent: unexpected ack")
+
+// FluentLogger implements the Logger interface by sending
+// events to Fluentd or Fluent Bit using a FluentClient.
+//
+// Each event is sent as a record containing
+// its level, message and fields.
+type FluentLogger struct {
+	Client *FluentClient
+	Level  LogLevel
+
+	Environment
+
+	noPanic bool
+	noExit  bool
+}
+
+// NewFluent creates and returns a new FluentLogger
+func NewFluent(client *FluentClient) *FluentLogger {
+	return &FluentLogger{Client: client, Level: LogLevelInfo}
+}
+
+// NoPanic prevents the logger from panicking on panic events
+func (fl *FluentLogger) NoPanic() {
+	fl.noPanic = true
+}
+
+// NoExit prevents the logger from exiting on fatal events
+func (fl *FluentLogger) NoExit() {
+	fl.noExit = true
+}
+
+// SetLevel sets the log level of the logger
+func (fl *FluentLogger) SetLevel(l LogLevel) {
+	fl.Level = l
+}
+
+// Enabled reports whether events with the given level are logged
+func (fl *FluentLogger) Enabled(lvl LogLevel) bool {
+	return fl.Client != nil && lvl >= fl.Level
+}
+
+// Debug creates a new debug event with the given message
+func (fl *FluentLogger) Debug(msg string) LogBuilder {
+	return newFluentLogBuilder(fl, msg, LogLevelDebug)
+}
+
+// Debugf creates a new debug event with the formatted message
+func (fl *FluentLogger) Debugf(format string, v ...any) LogBuilder {
+	return newFluentLogBuilder(fl, fmt.Sprintf(format, v...), LogLevelDebug)
+}
+
+// Info creates a new info event with the given message
+func (fl *FluentLogger) Info(msg string) LogBuilder {
+	return newFluentLogBuilder(fl, msg, LogLevelInfo)
+}
+
+// Infof creates a new info event with the formatted message
+func (fl *FluentLogger) Infof(format string, v ...any) LogBuilder {
+	return newFluentLogBuilder(fl, fmt.Sprintf(format, v...), LogLevelInfo)
+}
+
+// Warn creates a new warn event with the given message
+func (fl *FluentLogger) Warn(msg string) LogBuilder {
+	return newFluentLogBuilder(fl, msg, LogLevelWarn)
+}
+
+// Warnf creates a new warn event with the formatted message
+func (fl *FluentLogger) Warnf(format string, v ...any) LogBuilder {
+	return newFluentLogBuilder(fl, fmt.Sprintf(format, v...), LogLevelWarn)
+}
+
+// Error creates a new error event with the given message
+func (fl *FluentLogger) Error(msg string) LogBuilder {
+	return newFluentLogBuilder(fl, msg, LogLevelError)
+}
+
+// Errorf creates a new error event with the formatted message
+func (fl *FluentLogger) Errorf(format string, v ...any) LogBuilder {
+	return newFluentLogBuilder(fl, fmt.Sprintf(format, v...), LogLevelError)
+}
+
+// Fatal creates a new fatal event with the given message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (fl *FluentLogger) Fatal(msg string) LogBuilder {
+	return newFluentLogBuilder(fl, msg, LogLevelFatal)
+}
+
+// Fatalf creates a new fatal event with the formatted message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (fl *FluentLogger) Fatalf(format string, v ...any) LogBuilder {
+	return newFluentLogBuilder(fl, fmt.Sprintf(format, v...), LogLevelFatal)
+}
+
+// Panic creates a new panic event with the given message
+//
+// When sent, panic events will cause a panic
+func (fl *FluentLogger) Panic(msg string) LogBuilder {
+	return newFluentLogBuilder(fl, msg, LogLevelPanic)
+}
+
+// Panicf creates a new panic event with the formatted message
+//
+// When sent, panic events will cause a panic
+func (fl *FluentLogger) Panicf(format string, v ...any) LogBuilder {
+	return newFluentLogBuilder(fl, fmt.Sprintf(format, v...), LogLevelPanic)
+}
+
+func newFluentLogBuilder(fl *FluentLogger, msg string, lvl LogLevel) LogBuilder {
+	if !fl.Enabled(lvl) {
+		return NopLogBuilder{}
+	}
+	return newEventLogBuilder(lvl, msg, fl.Now, fl.send)
+}
+
+// LogEvent logs a collected event, keeping its time
+func (fl *FluentLogger) LogEvent(e *Event) {
+	if fl.Enabled(e.Level) {
+		fl.send(e)
+	}
+}
+
+// send encodes the event as a Forward protocol entry and
+// passes it to the client. Fatal and panic events are flushed
+// immediately so that they aren't lost when the program
+// terminates. Errors are passed to the client's
+// error handler.
+func (fl *FluentLogger) send(e *Event) {
+	buf := &bytes.Buffer{}
+	msgpackWriteArrayHeader(buf, 2)
+	msgpackWriteEventTime(buf, e.Time)
+	msgpackWriteMapHeader(buf, len(e.Fields)+2)
+	msgpackWriteString(buf, "level")
+	msgpackWriteString(buf, e.Level.String())
+	msgpackWriteString(buf, "msg")
+	msgpackWriteString(buf, e.Message)
+	for _, f := range e.Fields {
+		msgpackWriteString(buf, f.Key)
+		msgpackWriteField(buf, f)
+	}
+	fl.Client.handleError(fl.Client.enqueue(buf.Bytes()))
+
+	if e.Level == LogLevelFatal && !fl.noExit {
+		fl.Client.handleError(fl.Client.Flush())
+		fl.exit()
+	} else if e.Level == LogLevelPanic && !fl.noPanic {
+		fl.Client.handleError(fl.Client.Flush())
+		fl.doPanic(NewPanicEvent(e))
+	}
+}
+
+// FluentClient batches entries and sends them to Fluentd or
+// Fluent Bit using the Forward protocol in PackedForward mode,
+// over TCP or a Unix socket.
+//
+// Entries are sent when the batch is full, when the flush
+// interval elapses, or when Flush or Close is called. If the
+// connection fails, the client reconnects and retries with
+// exponential backoff.
+type FluentClient struct {
+	// Tag is the tag events are sent with
+	Tag string
+
+	// DialTimeout is the timeout for establishing a connection
+	DialTimeout time.Duration
+	// WriteTimeout is the timeout for writing a single batch
+	WriteTimeout time.Duration
+
+	// RequireAck enables the chunk option, which makes the
+	// server acknowledge every batch. Batches that aren't
+	// acknowledged within AckTimeout are retried, providing
+	// at-least-once delivery.
+	RequireAck bool
+	// AckTimeout is the maximum time to wait for an ack
+	AckTimeout time.Duration
+
+	Batching
+
+	network string
+	addr    string
+
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewFluentClient creates a new FluentClient that sends entries
+// with the given tag to the given address, and starts a goroutine
+// that sends them in the background every flush interval. The
+// network should be "tcp" or "unix".
+func NewFluentClient(network, addr, tag string, interval time.Duration) *FluentClient {
+	fc := &FluentClient{
+		Tag:          tag,
+		DialTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		AckTimeout:   30 * time.Second,
+		Batching: Batching{
+			BatchSize:      500,
+			MaxPending:     10000,
+			MaxRetries:     5,
+			InitialBackoff: 100 * time.Millisecond,
+			MaxBackoff:     30 * time.Second,
+		},
+
+		network: network,
+		addr:    addr,
+	}
+	fc.startBatching(interval, fc.prepare)
+	return fc
+}
+
+// Close stops the background goroutine, sends
+// any pending entries and closes the connection
+func (fc *FluentClient) Close() error {
+	err := fc.Batching.Close()
+
+	fc.sendMu.Lock()
+	defer fc.sendMu.Unlock()
+	if fc.conn != nil {
+		fc.conn.Close()
+		fc.conn = nil
+	}
+	return err
+}
+
+// prepare encodes a batch as a PackedForward message and returns
+// a function that sends it, reconnecting if needed. The batch
+// keeps the same chunk ID across retries, so that the server
+// can deduplicate it.
+func (fc *FluentClient) prepare(batch [][]byte) (batchAttempt, error) {
+	entries := &bytes.Buffer{}
+	for _, entry := range batch {
+		entries.Write(entry)
+	}
+
+	var chunk string
+	if fc.RequireAck {
+		id := make([]byte, 16)
+		rand.Read(id)
+		chunk = base64.StdEncoding.EncodeToString(id)
+	}
+
+	msg := &bytes.Buffer{}
+	msgpackWriteArrayHeader(msg, 3)
+	msgpackWriteString(msg, fc.Tag)
+	msgpackWriteBin(msg, entries.Bytes())
+	if chunk != "" {
+		msgpackWriteMapHeader(msg, 2)
+		msgpackWriteString(msg, "chunk")
+		msgpackWriteString(msg, chunk)
+	} else {
+		msgpackWriteMapHeader(msg, 1)
+	}
+	msgpackWriteString(msg, "size")
+	msgpackWriteUint(msg, uint64(len(batch)))
+
+	return func() (time.Duration, bool, error) {
+		err := fc.write(msg.Bytes(), chunk)
+		if err != nil && fc.conn != nil {
+			fc.conn.Close()
+			fc.conn = nil
+		}
+		return -1, true, err
+	}, nil
+}
+
+// write writes a message to the connection, connecting first if
+// needed, and waits for an ack if chunk isn't empty. The send
+// mutex must be held.
+func (fc *FluentClient) write(msg []byte, chunk string) error {
+	if fc.conn == nil {
+		conn, err := net.DialTimeout(fc.network, fc.addr, fc.DialTimeout)
+		if err != nil {
+			return err
+		}
+		fc.conn = conn
+		fc.reader = bufio.NewReader(conn)
+	}
+
+	if fc.WriteTimeout > 0 {
+		fc.conn.SetWriteDeadline(time.Now().Add(fc.WriteTimeout))
+	}
+	if _, err := fc.conn.Write(msg); err != nil {
+		return err
+	}
+	if chunk == "" {
+		return nil
+	}
+
+	if fc.AckTimeout > 0 {
+		fc.conn.SetReadDeadline(time.Now().Add(fc.AckTimeout))
+	}
+	res, err := msgpackReadStringMap(fc.reader)
+	if err != nil {
+		return err
+	}
+	if res["ack"] != chunk {
+		return ErrFluentAck
+	}
+	return nil
+}
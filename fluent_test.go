@@ -0,0 +1,360 @@
+package logger_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"go.elara.ws/logger"
+)
+
+// readMsgpack decodes a single msgpack value from r. It only
+// supports the types produced by the logger's encoder.
+func readMsgpack(r *bufio.Reader) (any, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	readN := func(n int) ([]byte, error) {
+		data := make([]byte, n)
+		_, err := io.ReadFull(r, data)
+		return data, err
+	}
+	readLen := func(size int) (int, error) {
+		data, err := readN(size)
+		if err != nil {
+			return 0, err
+		}
+		n := 0
+		for _, b := range data {
+			n = n<<8 | int(b)
+		}
+		return n, nil
+	}
+	readArray := func(n int) (any, error) {
+		out := make([]any, n)
+		for i := range out {
+			if out[i], err = readMsgpack(r); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	}
+	readMap := func(n int) (any, error) {
+		out := make(map[string]any, n)
+		for i := 0; i < n; i++ {
+			k, err := readMsgpack(r)
+			if err != nil {
+				return nil, err
+			}
+			v, err := readMsgpack(r)
+			if err != nil {
+				return nil, err
+			}
+			out[fmt.Sprint(k)] = v
+		}
+		return out, nil
+	}
+
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b&0xf0 == 0x80:
+		return readMap(int(b & 0x0f))
+	case b&0xf0 == 0x90:
+		return readArray(int(b & 0x0f))
+	case b&0xe0 == 0xa0:
+		data, err := readN(int(b & 0x1f))
+		return string(data), err
+	}
+
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2, 0xc3:
+		return b == 0xc3, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := readLen(1 << (b - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		return readN(n)
+	case 0xca:
+		data, err := readN(4)
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data))), err
+	case 0xcb:
+		data, err := readN(8)
+		return math.Float64frombits(binary.BigEndian.Uint64(data)), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		data, err := readN(1 << (b - 0xcc))
+		var u uint64
+		for _, b := range data {
+			u = u<<8 | uint64(b)
+		}
+		return int64(u), err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (b - 0xd0)
+		data, err := readN(size)
+		var u uint64
+		for _, b := range data {
+			u = u<<8 | uint64(b)
+		}
+		shift := 64 - 8*size
+		return int64(u<<shift) >> shift, err
+	case 0xd7:
+		data, err := readN(9)
+		if err != nil {
+			return nil, err
+		}
+		sec := binary.BigEndian.Uint32(data[1:5])
+		nsec := binary.BigEndian.Uint32(data[5:9])
+		return time.Unix(int64(sec), int64(nsec)), nil
+	case 0xd9, 0xda, 0xdb:
+		n, err := readLen(1 << (b - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		data, err := readN(n)
+		return string(data), err
+	case 0xdc, 0xdd:
+		n, err := readLen(2 << (b - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return readArray(n)
+	case 0xde, 0xdf:
+		n, err := readLen(2 << (b - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return readMap(n)
+	}
+	return nil, fmt.Errorf("unsupported msgpack type: %#x", b)
+}
+
+// fluentRecord is an entry received by fluentServer
+type fluentRecord struct {
+	tag    string
+	time   time.Time
+	record map[string]any
+}
+
+// fluentServer is an in-process Forward protocol server
+type fluentServer struct {
+	ln net.Listener
+
+	mu      sync.Mutex
+	records []fluentRecord
+	// dropAcks is the amount of messages that will be
+	// received without being acknowledged
+	dropAcks int
+	messages int
+}
+
+func newFluentServer(t *testing.T) *fluentServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := &fluentServer{ln: ln}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go fs.handle(conn)
+		}
+	}()
+	return fs
+}
+
+func (fs *fluentServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		val, err := readMsgpack(r)
+		if err != nil {
+			return
+		}
+		msg := val.([]any)
+		tag := msg[0].(string)
+		option := msg[2].(map[string]any)
+
+		fs.mu.Lock()
+		fs.messages++
+		if fs.dropAcks > 0 {
+			// Simulate a server that crashes before
+			// acknowledging the message
+			fs.dropAcks--
+			fs.mu.Unlock()
+			return
+		}
+
+		er := bufio.NewReader(bytes.NewReader(msg[1].([]byte)))
+		for {
+			entry, err := readMsgpack(er)
+			if errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				fs.mu.Unlock()
+				return
+			}
+			e := entry.([]any)
+			fs.records = append(fs.records, fluentRecord{tag, e[0].(time.Time), e[1].(map[string]any)})
+		}
+		fs.mu.Unlock()
+
+		if chunk, ok := option["chunk"].(string); ok {
+			conn.Write(append([]byte{0x81, 0xa3}, "ack"...))
+			conn.Write(append([]byte{0xa0 | byte(len(chunk))}, chunk...))
+		}
+	}
+}
+
+func newFluentTest(t *testing.T, fs *fluentServer) *logger.FluentClient {
+	t.Helper()
+	fc := logger.NewFluentClient("tcp", fs.ln.Addr().String(), "app.test", time.Hour)
+	startBatchingTest(t, fc, &fc.Batching)
+	return fc
+}
+
+func TestFluent(t *testing.T) {
+	t.Run("record", func(t *testing.T) {
+		fs := newFluentServer(t)
+		fc := newFluentTest(t, fs)
+		// Wait for acks so that the server has
+		// received the records when Flush returns
+		fc.RequireAck = true
+		fluentlog := logger.NewFluent(fc)
+
+		fluentlog.Warn("Test").
+			Int("n", -1234).
+			Uint64("u", math.MaxUint64>>1).
+			Float64("f", 1.5).
+			Bool("ok", true).
+			Bytes("b", []byte{1, 2}).
+			Any("any", map[string]int{"x": 1}).
+			Err(errors.New("err")).
+			Send()
+		fluentlog.Info("Test 2").Send()
+
+		if err := fc.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		fs.mu.Lock()
+		defer fs.mu.Unlock()
+		if got, want := len(fs.records), 2; got != want {
+			t.Fatalf("got: %d records, want: %d", got, want)
+		}
+		rec := fs.records[0]
+		if got, want := rec.tag, "app.test"; got != want {
+			t.Errorf("got: %s tag, want: %s", got, want)
+		}
+		if time.Since(rec.time) > time.Minute {
+			t.Errorf("unexpected time: %s", rec.time)
+		}
+		for k, want := range map[string]any{
+			"level": "warn",
+			"msg":   "Test",
+			"n":     int64(-1234),
+			"u":     int64(math.MaxUint64 >> 1),
+			"f":     1.5,
+			"ok":    true,
+			"error": "err",
+		} {
+			if got := rec.record[k]; got != want {
+				t.Errorf("got: %v (%T) for %s, want: %v (%T)", got, got, k, want, want)
+			}
+		}
+		if got, want := fmt.Sprint(rec.record["b"], rec.record["any"]), "[1 2] map[x:1]"; got != want {
+			t.Errorf("got: %s, want: %s", got, want)
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		fc := newFluentTest(t, newFluentServer(t))
+		fl := logger.NewFluent(fc)
+		assertClosedError(t, fc, &fc.Batching, func() { fl.Info("Test").Send() })
+	})
+
+	t.Run("ack", func(t *testing.T) {
+		fs := newFluentServer(t)
+		fs.dropAcks = 1
+		fc := newFluentTest(t, fs)
+		fc.RequireAck = true
+		fluentlog := logger.NewFluent(fc)
+
+		fluentlog.Info("Test").Send()
+		if err := fc.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		fs.mu.Lock()
+		defer fs.mu.Unlock()
+		if got, want := fs.messages, 2; got != want {
+			t.Errorf("got: %d messages, want: %d", got, want)
+		}
+		if got, want := len(fs.records), 1; got != want {
+			t.Errorf("got: %d records, want: %d", got, want)
+		}
+	})
+
+	t.Run("reconnect", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr := ln.Addr().String()
+		ln.Close()
+
+		fc := logger.NewFluentClient("tcp", addr, "app.test", time.Hour)
+		fc.InitialBackoff = 10 * time.Millisecond
+		fc.MaxRetries = 50
+		fc.RequireAck = true
+		defer fc.Close()
+		fluentlog := logger.NewFluent(fc)
+		fluentlog.Info("Test").Send()
+
+		errs := make(chan error, 1)
+		go func() { errs <- fc.Flush() }()
+
+		time.Sleep(50 * time.Millisecond)
+		ln, err = net.Listen("tcp", addr)
+		if err != nil {
+			t.Skip("couldn't reuse address:", err)
+		}
+		fs := &fluentServer{ln: ln}
+		defer ln.Close()
+		go func() {
+			conn, err := ln.Accept()
+			if err == nil {
+				fs.handle(conn)
+			}
+		}()
+
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+		fs.mu.Lock()
+		defer fs.mu.Unlock()
+		if got, want := len(fs.records), 1; got != want {
+			t.Errorf("got: %d records, want: %d", got, want)
+		}
+	})
+}
@@ -0,0 +1,453 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+var _ Logger = (*GELFLogger)(nil)
+
+// GELFLogger implements the Logger interface
+// using GELF 1.1 for log messages.
+type GELFLogger struct {
+	Out   io.Writer
+	Level LogLevel
+
+	// Host is the value of the GELF host field
+	Host string
+
+	// Delimiter is written after every message.
+	// GELF TCP inputs require a null byte.
+	Delimiter string
+
+	ErrorHandling
+	Environment
+
+	noPanic bool
+	noExit  bool
+}
+
+// NewGELF creates and returns a new GELFLogger.
+// The host field is set to the system's hostname.
+func NewGELF(out io.Writer) *GELFLogger {
+	host, _ := os.Hostname()
+	return &GELFLogger{
+		Out:       out,
+		Level:     LogLevelInfo,
+		Host:      host,
+		Delimiter: "\n",
+	}
+}
+
+// NoPanic prevents the logger from panicking on panic events
+func (gl *GELFLogger) NoPanic() {
+	gl.noPanic = true
+}
+
+// NoExit prevents the logger from exiting on fatal events
+func (gl *GELFLogger) NoExit() {
+	gl.noExit = true
+}
+
+// SetLevel sets the log level of the logger
+func (gl *GELFLogger) SetLevel(l LogLevel) {
+	gl.Level = l
+}
+
+// Enabled reports whether events with the given level are logged
+func (gl *GELFLogger) Enabled(lvl LogLevel) bool {
+	return gl.Out != io.Discard && lvl >= gl.Level
+}
+
+// Debug creates a new debug event with the given message
+func (gl *GELFLogger) Debug(msg string) LogBuilder {
+	return newGELFLogBuilder(gl, msg, LogLevelDebug)
+}
+
+// Debugf creates a new debug event with the formatted message
+func (gl *GELFLogger) Debugf(format string, v ...any) LogBuilder {
+	return newGELFLogBuilder(gl, fmt.Sprintf(format, v...), LogLevelDebug)
+}
+
+// Info creates a new info event with the given message
+func (gl *GELFLogger) Info(msg string) LogBuilder {
+	return newGELFLogBuilder(gl, msg, LogLevelInfo)
+}
+
+// Infof creates a new info event with the formatted message
+func (gl *GELFLogger) Infof(format string, v ...any) LogBuilder {
+	return newGELFLogBuilder(gl, fmt.Sprintf(format, v...), LogLevelInfo)
+}
+
+// Warn creates a new warn event with the given message
+func (gl *GELFLogger) Warn(msg string) LogBuilder {
+	return newGELFLogBuilder(gl, msg, LogLevelWarn)
+}
+
+// Warnf creates a new warn event with the formatted message
+func (gl *GELFLogger) Warnf(format string, v ...any) LogBuilder {
+	return newGELFLogBuilder(gl, fmt.Sprintf(format, v...), LogLevelWarn)
+}
+
+// Error creates a new error event with the given message
+func (gl *GELFLogger) Error(msg string) LogBuilder {
+	return newGELFLogBuilder(gl, msg, LogLevelError)
+}
+
+// Errorf creates a new error event with the formatted message
+func (gl *GELFLogger) Errorf(format string, v ...any) LogBuilder {
+	return newGELFLogBuilder(gl, fmt.Sprintf(format, v...), LogLevelError)
+}
+
+// Fatal creates a new fatal event with the given message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (gl *GELFLogger) Fatal(msg string) LogBuilder {
+	return newGELFLogBuilder(gl, msg, LogLevelFatal)
+}
+
+// Fatalf creates a new fatal event with the formatted message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (gl *GELFLogger) Fatalf(format string, v ...any) LogBuilder {
+	return newGELFLogBuilder(gl, fmt.Sprintf(format, v...), LogLevelFatal)
+}
+
+// Panic creates a new panic event with the given message
+//
+// When sent, panic events will cause a panic
+func (gl *GELFLogger) Panic(msg string) LogBuilder {
+	return newGELFLogBuilder(gl, msg, LogLevelPanic)
+}
+
+// Panicf creates a new panic event with the formatted message
+//
+// When sent, panic events will cause a panic
+func (gl *GELFLogger) Panicf(format string, v ...any) LogBuilder {
+	return newGELFLogBuilder(gl, fmt.Sprintf(format, v...), LogLevelPanic)
+}
+
+// GELFLogBuilder implements the LogBuilder interface
+// using GELF 1.1 for log messages
+type GELFLogBuilder struct {
+	l   *GELFLogger
+	lvl LogLevel
+	out writer
+	pe  *PanicEvent
+}
+
+func newGELFLogBuilder(gl *GELFLogger, msg string, lvl LogLevel) LogBuilder {
+	return newGELFLogBuilderClock(gl, msg, lvl, gl.Now)
+}
+
+// newGELFLogBuilderClock creates a new builder for an
+// event whose time is returned by now
+func newGELFLogBuilderClock(gl *GELFLogger, msg string, lvl LogLevel, now func() time.Time) LogBuilder {
+	if !gl.Enabled(lvl) {
+		return NopLogBuilder{}
+	}
+	lb := &GELFLogBuilder{
+		out: writer{&bytes.Buffer{}, gl.Out},
+		lvl: lvl,
+		l:   gl,
+	}
+	ms := now().UnixMilli()
+	lb.out.WriteString(`{"version":"1.1","host":`)
+	writeJSONString(lb.out.Buffer, gl.Host)
+	lb.out.WriteString(`,"short_message":`)
+	writeJSONString(lb.out.Buffer, msg)
+	lb.out.WriteString(`,"timestamp":`)
+	lb.out.WriteString(strconv.FormatInt(ms/1000, 10))
+	lb.out.WriteByte('.')
+	lb.out.WriteString(fmt.Sprintf("%03d", ms%1000))
+	lb.out.WriteString(`,"level":`)
+	lb.out.WriteString(strconv.Itoa(syslogSeverities[lvl]))
+	if lvl == LogLevelPanic && !gl.noPanic {
+		return capturePanic(lb, msg, now, &lb.pe)
+	}
+	return lb
+}
+
+// LogEvent logs a collected event, keeping its time
+func (gl *GELFLogger) LogEvent(e *Event) {
+	now := func() time.Time { return e.Time }
+	e.Apply(newGELFLogBuilderClock(gl, e.Message, e.Level, now)).Send()
+}
+
+// writeKey writes a GELF additional field key to the buffer.
+// The key is prefixed with an underscore, and any characters
+// not allowed by the spec are replaced with underscores.
+func (glb *GELFLogBuilder) writeKey(k string) {
+	glb.out.WriteString(`,"_`)
+	for i := 0; i < len(k); i++ {
+		c := k[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9',
+			c == '_', c == '.', c == '-':
+			glb.out.WriteByte(c)
+		default:
+			glb.out.WriteByte('_')
+		}
+	}
+	// _id is reserved by the spec, so it can't be used
+	// as an additional field
+	if k == "id" {
+		glb.out.WriteByte('_')
+	}
+	glb.out.WriteString(`":`)
+}
+
+// Int adds an int field to the output
+func (glb *GELFLogBuilder) Int(key string, val int) LogBuilder {
+	return glb.Int64(key, int64(val))
+}
+
+// Int64 adds an int64 field to the output
+func (glb *GELFLogBuilder) Int64(key string, val int64) LogBuilder {
+	glb.writeKey(key)
+	glb.out.WriteString(strconv.FormatInt(val, 10))
+	return glb
+}
+
+// Int32 adds an int32 field to the output
+func (glb *GELFLogBuilder) Int32(key string, val int32) LogBuilder {
+	return glb.Int64(key, int64(val))
+}
+
+// Int16 adds an int16 field to the output
+func (glb *GELFLogBuilder) Int16(key string, val int16) LogBuilder {
+	return glb.Int64(key, int64(val))
+}
+
+// Int8 adds an int8 field to the output
+func (glb *GELFLogBuilder) Int8(key string, val int8) LogBuilder {
+	return glb.Int64(key, int64(val))
+}
+
+// Uint adds a uint field to the output
+func (glb *GELFLogBuilder) Uint(key string, val uint) LogBuilder {
+	return glb.Uint64(key, uint64(val))
+}
+
+// Uint64 adds a uint64 field to the output
+func (glb *GELFLogBuilder) Uint64(key string, val uint64) LogBuilder {
+	glb.writeKey(key)
+	glb.out.WriteString(strconv.FormatUint(val, 10))
+	return glb
+}
+
+// Uint32 adds a uint32 field to the output
+func (glb *GELFLogBuilder) Uint32(key string, val uint32) LogBuilder {
+	return glb.Uint64(key, uint64(val))
+}
+
+// Uint16 adds a uint16 field to the output
+func (glb *GELFLogBuilder) Uint16(key string, val uint16) LogBuilder {
+	return glb.Uint64(key, uint64(val))
+}
+
+// Uint8 adds a uint8 field to the output
+func (glb *GELFLogBuilder) Uint8(key string, val uint8) LogBuilder {
+	return glb.Uint64(key, uint64(val))
+}
+
+// float adds a float of specified bitsize to the output
+func (glb *GELFLogBuilder) float(key string, val float64, bitsize int) LogBuilder {
+	glb.writeKey(key)
+	glb.out.WriteString(strconv.FormatFloat(val, 'f', -1, bitsize))
+	return glb
+}
+
+// Float64 adds a float64 field to the output
+func (glb *GELFLogBuilder) Float64(key string, val float64) LogBuilder {
+	return glb.float(key, val, 64)
+}
+
+// Float32 adds a float32 field to the output
+func (glb *GELFLogBuilder) Float32(key string, val float32) LogBuilder {
+	return glb.float(key, float64(val), 32)
+}
+
+// Stringer calls the String method of an fmt.Stringer
+// and adds the resulting string as a field to the output
+func (glb *GELFLogBuilder) Stringer(key string, s fmt.Stringer) LogBuilder {
+	return glb.Str(key, s.String())
+}
+
+// Bytes writes base64-encoded bytes as a field to the output
+func (glb *GELFLogBuilder) Bytes(key string, b []byte) LogBuilder {
+	return glb.Str(key, base64.StdEncoding.EncodeToString(b))
+}
+
+// Timestamp is a no-op because every GELF message
+// already contains a timestamp field
+func (glb *GELFLogBuilder) Timestamp() LogBuilder {
+	return glb
+}
+
+// Bool adds a bool as a field to the output. GELF only allows
+// strings and numbers as additional fields, so it's written
+// as the string "true" or "false".
+func (glb *GELFLogBuilder) Bool(key string, val bool) LogBuilder {
+	return glb.Str(key, strconv.FormatBool(val))
+}
+
+// Str adds a string as a field to the output
+func (glb *GELFLogBuilder) Str(key, val string) LogBuilder {
+	glb.writeKey(key)
+	writeJSONString(glb.out.Buffer, val)
+	return glb
+}
+
+// Any uses reflection to marshal any type and writes
+// the result as a field to the output. This is much slower
+// than the type-specific functions.
+//
+// GELF only allows strings and numbers as additional fields,
+// so any other JSON value, such as an object, is written
+// as a string containing its JSON encoding.
+func (glb *GELFLogBuilder) Any(key string, val any) LogBuilder {
+	data, err := json.Marshal(val)
+	if err != nil {
+		panic(err)
+	}
+	glb.writeKey(key)
+	switch c := data[0]; {
+	case c == '"', c == '-', c >= '0' && c <= '9':
+		glb.out.Write(data)
+	default:
+		writeJSONString(glb.out.Buffer, string(data))
+	}
+	return glb
+}
+
+// Err adds an error as a field to the output
+func (glb *GELFLogBuilder) Err(err error) LogBuilder {
+	return glb.Str("error", err.Error())
+}
+
+// Send sends the event to the output.
+//
+// After calling send, do not use the event again.
+func (glb *GELFLogBuilder) Send() {
+	glb.out.WriteByte('}')
+	glb.out.WriteString(glb.l.Delimiter)
+	glb.l.flush(glb.out)
+	if glb.lvl == LogLevelFatal && !glb.l.noExit {
+		glb.l.exit()
+	} else if glb.lvl == LogLevelPanic && !glb.l.noPanic {
+		glb.l.doPanic(glb.pe)
+	}
+}
+
+const (
+	// gelfChunkHeaderSize is the size of the header
+	// added to each chunk of a chunked GELF message
+	gelfChunkHeaderSize = 12
+	// gelfMaxChunks is the maximum amount of chunks
+	// a GELF message may be split into
+	gelfMaxChunks = 128
+)
+
+// ErrGELFMessageTooLarge is returned by GELFUDPWriter when a message
+// doesn't fit into the maximum amount of chunks allowed by GELF
+var ErrGELFMessageTooLarge = errors.New("gelf message too large")
+
+// GELFUDPWriter is an io.Writer that sends each write as a single
+// GELF message over UDP, compressing it with gzip and splitting it
+// into chunks if necessary. It is meant to be used as the output
+// of a GELFLogger.
+type GELFUDPWriter struct {
+	// ChunkSize is the maximum size of a single datagram.
+	// Messages larger than this will be chunked.
+	ChunkSize int
+
+	// Compress enables gzip compression of messages
+	Compress bool
+
+	conn net.Conn
+}
+
+// NewGELFUDPWriter creates a new GELFUDPWriter that sends
+// messages to the given UDP address
+func NewGELFUDPWriter(addr string) (*GELFUDPWriter, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &GELFUDPWriter{
+		ChunkSize: 1420,
+		Compress:  true,
+		conn:      conn,
+	}, nil
+}
+
+// Write sends p as a single GELF message. Trailing newlines
+// and null bytes are removed because every datagram is
+// already a separate message.
+func (gw *GELFUDPWriter) Write(p []byte) (int, error) {
+	data := bytes.TrimRight(p, "\n\x00")
+	if gw.Compress {
+		buf := &bytes.Buffer{}
+		zw := gzip.NewWriter(buf)
+		if _, err := zw.Write(data); err != nil {
+			return 0, err
+		}
+		if err := zw.Close(); err != nil {
+			return 0, err
+		}
+		data = buf.Bytes()
+	}
+
+	if len(data) <= gw.ChunkSize {
+		if _, err := gw.conn.Write(data); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	chunkData := gw.ChunkSize - gelfChunkHeaderSize
+	if chunkData <= 0 {
+		return 0, ErrGELFMessageTooLarge
+	}
+	count := (len(data) + chunkData - 1) / chunkData
+	if count > gelfMaxChunks {
+		return 0, ErrGELFMessageTooLarge
+	}
+
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return 0, err
+	}
+
+	chunk := make([]byte, 0, gw.ChunkSize)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * chunkData
+		if end > len(data) {
+			end = len(data)
+		}
+		chunk = append(chunk[:0], 0x1e, 0x0f)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, data[i*chunkData:end]...)
+		if _, err := gw.conn.Write(chunk); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Close closes the underlying connection
+func (gw *GELFUDPWriter) Close() error {
+	return gw.conn.Close()
+}
@@ -0,0 +1,199 @@
+package logger_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"go.elara.ws/logger"
+)
+
+func TestGELF(t *testing.T) {
+	t.Run("fields", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		gelflog := logger.NewGELF(out)
+		gelflog.Host = "test"
+
+		gelflog.Warn("Test \"quoted\"").
+			Int("n", 1234).
+			Str("id", "abc").
+			Str("with space", "x").
+			Err(errors.New("err")).
+			Send()
+
+		var msg map[string]any
+		if err := json.Unmarshal(out.Bytes(), &msg); err != nil {
+			t.Fatal(err)
+		}
+
+		want := map[string]any{
+			"version":       "1.1",
+			"host":          "test",
+			"short_message": "Test \"quoted\"",
+			"level":         float64(4),
+			"_n":            float64(1234),
+			"_id_":          "abc",
+			"_with_space":   "x",
+			"_error":        "err",
+		}
+		for k, v := range want {
+			if msg[k] != v {
+				t.Errorf("%s: got: %v, want: %v", k, msg[k], v)
+			}
+		}
+		if _, ok := msg["timestamp"].(float64); !ok {
+			t.Errorf("expected numeric timestamp, got %v", msg["timestamp"])
+		}
+	})
+
+	t.Run("field-types", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		gelflog := logger.NewGELF(out)
+
+		gelflog.Info("Test").
+			Bool("ok", true).
+			Any("obj", map[string]int{"a": 1}).
+			Any("num", 1.5).
+			Any("str", "x").
+			Any("nil", nil).
+			Send()
+
+		var msg map[string]any
+		if err := json.Unmarshal(out.Bytes(), &msg); err != nil {
+			t.Fatal(err)
+		}
+
+		want := map[string]any{
+			"_ok":  "true",
+			"_obj": `{"a":1}`,
+			"_num": 1.5,
+			"_str": "x",
+			"_nil": "null",
+		}
+		for k, v := range want {
+			if msg[k] != v {
+				t.Errorf("%s: got: %v, want: %v", k, msg[k], v)
+			}
+		}
+	})
+
+	t.Run("udp", func(t *testing.T) {
+		conn, gw := newGELFListener(t)
+		gw.Compress = false
+
+		gelflog := logger.NewGELF(gw)
+		gelflog.Info("Test").Int("n", 1234).Send()
+
+		msg := readGELF(t, conn)
+		if got, want := msg["short_message"], "Test"; got != want {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+		if got, want := msg["_n"], float64(1234); got != want {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("chunked", func(t *testing.T) {
+		conn, gw := newGELFListener(t)
+		gw.ChunkSize = 64
+
+		long := strings.Repeat("0123456789", 100)
+		gelflog := logger.NewGELF(gw)
+		gelflog.Info(long).Send()
+
+		msg := readGELF(t, conn)
+		if got, want := msg["short_message"], long; got != want {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("too-large", func(t *testing.T) {
+		_, gw := newGELFListener(t)
+		gw.ChunkSize = 16
+		gw.Compress = false
+
+		_, err := gw.Write(bytes.Repeat([]byte{'a'}, 4096))
+		if !errors.Is(err, logger.ErrGELFMessageTooLarge) {
+			t.Errorf("got: %v, want: %v", err, logger.ErrGELFMessageTooLarge)
+		}
+	})
+}
+
+func newGELFListener(t *testing.T) (net.PacketConn, *logger.GELFUDPWriter) {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	gw, err := logger.NewGELFUDPWriter(conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { gw.Close() })
+	return conn, gw
+}
+
+// readGELF reads a single GELF message from conn,
+// reassembling and decompressing it if necessary
+func readGELF(t *testing.T, conn net.PacketConn) map[string]any {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var chunks [][]byte
+	var data []byte
+	buf := make([]byte, 65536)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkt := append([]byte(nil), buf[:n]...)
+
+		if len(pkt) < 2 || pkt[0] != 0x1e || pkt[1] != 0x0f {
+			data = pkt
+			break
+		}
+
+		seq, count := pkt[10], pkt[11]
+		if chunks == nil {
+			chunks = make([][]byte, count)
+		}
+		chunks[seq] = pkt[12:]
+
+		done := true
+		for _, c := range chunks {
+			if c == nil {
+				done = false
+			}
+		}
+		if done {
+			data = bytes.Join(chunks, nil)
+			break
+		}
+	}
+
+	if len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err = io.ReadAll(zr)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var msg map[string]any
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
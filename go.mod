@@ -5,10 +5,10 @@ go 1.18
 require (
 	github.com/gookit/color v1.5.1
 	github.com/mattn/go-isatty v0.0.14
+	golang.org/x/sys v0.0.0-20210927094055-39ccf1dd6fa6
 )
 
 require (
 	github.com/stretchr/testify v1.8.0 // indirect
 	github.com/xo/terminfo v0.0.0-20210125001918-ca9a967f8778 // indirect
-	golang.org/x/sys v0.0.0-20210927094055-39ccf1dd6fa6 // indirect
 )
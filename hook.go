@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// Hook processes events before they're written, such as
+// to add fields, change their level, or filter them out.
+type Hook interface {
+	// Run is called with every event before it's written.
+	// It may modify the event, and returns false if the
+	// event should be dropped.
+	Run(e *Event) bool
+}
+
+// HookFunc is a function that implements the Hook interface
+type HookFunc func(e *Event) bool
+
+// Run calls the function
+func (fn HookFunc) Run(e *Event) bool {
+	return fn(e)
+}
+
+// Hooks contains the hooks of a logger. It's embedded in
+// loggers that support hooks.
+//
+// Once a hook has been added, hooks run on events of every level,
+// and the logger's level is checked after they've run. This means
+// hooks may raise the level of an event so that it's logged, and
+// events whose level is lowered below the logger's level are
+// dropped. Dropping or lowering the level of a fatal or panic
+// event prevents the program from exiting or panicking.
+type Hooks struct {
+	mu    sync.RWMutex
+	hooks []Hook
+}
+
+// AddHook adds a hook that runs on every event,
+// after any hooks that have already been added
+func (hs *Hooks) AddHook(h Hook) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.hooks = append(hs.hooks, h)
+}
+
+// hooked reports whether any hooks have been added
+func (hs *Hooks) hooked() bool {
+	hs.mu.RLock()
+	defer hs.mu.RUnlock()
+	return len(hs.hooks) > 0
+}
+
+// runHooks runs all the hooks on e, and reports
+// whether the event should be written
+func (hs *Hooks) runHooks(e *Event) bool {
+	hs.mu.RLock()
+	hooks := hs.hooks
+	hs.mu.RUnlock()
+
+	for _, h := range hooks {
+		if !h.Run(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// withHooks returns a builder that collects the event and runs
+// the hooks on it when it's sent. If the event isn't dropped,
+// it's then written to the builder returned by next.
+func (hs *Hooks) withHooks(lvl LogLevel, msg string, now func() time.Time, next func(lvl LogLevel, msg string) LogBuilder) LogBuilder {
+	return newEventLogBuilder(lvl, msg, now, func(e *Event) {
+		if !hs.runHooks(e) {
+			return
+		}
+		e.Apply(next(e.Level, e.Message)).Send()
+	})
+}
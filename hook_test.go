@@ -0,0 +1,169 @@
+package logger_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"go.elara.ws/logger"
+	"go.elara.ws/logger/logtest"
+)
+
+// pidHook adds a pid field to every event
+var pidHook = logger.HookFunc(func(e *logger.Event) bool {
+	e.Fields = append(e.Fields, logger.Field{Key: "pid", Kind: logger.KindInt, Value: int64(1234)})
+	return true
+})
+
+// dropHealthHook drops events with the message "health check"
+var dropHealthHook = logger.HookFunc(func(e *logger.Event) bool {
+	return e.Message != "health check"
+})
+
+func TestHooks(t *testing.T) {
+	t.Run("json-fields", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		jl := logger.NewJSON(buf)
+		jl.AddHook(pidHook)
+
+		jl.Info("Test").Str("a", "b").Send()
+
+		expected := `{"msg":"Test","level":"info","a":"b","pid":1234}`
+		if buf.String() != expected {
+			t.Errorf("got: %s, want: %s", buf.String(), expected)
+		}
+	})
+
+	t.Run("pretty-drop", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		pl := logger.NewPretty(buf)
+		pl.AddHook(dropHealthHook)
+
+		pl.Info("health check").Send()
+		if buf.Len() != 0 {
+			t.Errorf("expected dropped event, got: %s", buf.String())
+		}
+
+		pl.Info("Test").Send()
+		if !strings.Contains(buf.String(), "Test") {
+			t.Errorf("expected event, got: %s", buf.String())
+		}
+	})
+
+	t.Run("cli-level", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		cl := logger.NewCLI(buf)
+		cl.AddHook(logger.HookFunc(func(e *logger.Event) bool {
+			if e.Message == "Test" {
+				e.Level = logger.LogLevelError
+			}
+			return true
+		}))
+
+		cl.Info("Test").Send()
+		if !strings.HasPrefix(buf.String(), " -> Test") {
+			t.Errorf("expected error prefix, got: %s", buf.String())
+		}
+	})
+
+	t.Run("order", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		jl := logger.NewJSON(buf)
+		jl.AddHook(dropHealthHook)
+		jl.AddHook(logger.HookFunc(func(e *logger.Event) bool {
+			t.Error("hook ran after the event was dropped")
+			return true
+		}))
+
+		jl.Info("health check").Send()
+		if buf.Len() != 0 {
+			t.Errorf("expected dropped event, got: %s", buf.String())
+		}
+	})
+
+	t.Run("promote", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		jl := logger.NewJSON(buf)
+		jl.AddHook(logger.HookFunc(func(e *logger.Event) bool {
+			if e.Message == "Disk full" {
+				e.Level = logger.LogLevelError
+			}
+			return true
+		}))
+
+		jl.Debug("Disk full").Send()
+		jl.Debug("Test").Send()
+
+		expected := `{"msg":"Disk full","level":"error"}`
+		if buf.String() != expected {
+			t.Errorf("got: %s, want: %s", buf.String(), expected)
+		}
+	})
+
+	t.Run("demote", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		jl := logger.NewJSON(buf)
+		jl.AddHook(logger.HookFunc(func(e *logger.Event) bool {
+			e.Level = logger.LogLevelDebug
+			return true
+		}))
+
+		jl.Info("Test").Send()
+		if buf.Len() != 0 {
+			t.Errorf("expected event below the level to be dropped, got: %s", buf.String())
+		}
+	})
+
+	t.Run("fatal-dropped", func(t *testing.T) {
+		jl := logger.NewJSON(&bytes.Buffer{})
+		jl.ExitFunc = func(int) { t.Error("dropped fatal event caused an exit") }
+		jl.AddHook(logger.HookFunc(func(e *logger.Event) bool { return false }))
+
+		jl.Fatal("Test").Send()
+	})
+
+	t.Run("multi", func(t *testing.T) {
+		o1, o2 := logtest.New(), logtest.New()
+		ml := logger.NewMulti(o1, o2)
+
+		runs := 0
+		ml.AddHook(logger.HookFunc(func(e *logger.Event) bool {
+			runs++
+			e.Fields = append(e.Fields, logger.Field{Key: "version", Kind: logger.KindString, Value: "1.0.0"})
+			return true
+		}))
+		ml.AddHook(dropHealthHook)
+
+		ml.Info("Test").Send()
+		ml.Warnf("health %s", "check").Send()
+
+		if runs != 2 {
+			t.Errorf("got: %d hook runs, want: 2", runs)
+		}
+		for _, o := range []*logtest.Observer{o1, o2} {
+			if got, want := o.Len(), 1; got != want {
+				t.Fatalf("got: %d events, want: %d", got, want)
+			}
+			if !o.Logged(logger.LogLevelInfo, "Test", logtest.Str("version", "1.0.0")) {
+				t.Errorf("expected enriched event, got: %v", o.Events())
+			}
+		}
+	})
+
+	t.Run("multi-panic", func(t *testing.T) {
+		ml := logger.NewMulti(logtest.New())
+		ml.AddHook(pidHook)
+
+		var got any
+		ml.PanicFunc = func(v any) { got = v }
+		ml.Panic("Test").Send()
+
+		pe, ok := got.(*logger.PanicEvent)
+		if !ok {
+			t.Fatalf("got: %T, want: *logger.PanicEvent", got)
+		}
+		if len(pe.Fields) != 1 || pe.Fields[0].Key != "pid" {
+			t.Errorf("expected pid field in panic event, got: %v", pe.Fields)
+		}
+	})
+}
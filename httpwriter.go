@@ -0,0 +1,232 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var _ io.WriteCloser = (*HTTPWriter)(nil)
+
+// HTTPBodyFormat controls how a batch of events is
+// encoded into the body of an HTTP request
+type HTTPBodyFormat struct {
+	// ContentType is the value of the Content-Type header
+	ContentType string
+	// Encode writes the batch of events to buf. Events don't
+	// include a trailing newline. If it returns an error,
+	// the batch is dropped.
+	Encode func(buf *bytes.Buffer, events [][]byte) error
+}
+
+// HTTPJSONArray encodes batches as a JSON array of events
+var HTTPJSONArray = HTTPBodyFormat{
+	ContentType: "application/json",
+	Encode: func(buf *bytes.Buffer, events [][]byte) error {
+		buf.WriteByte('[')
+		for i, event := range events {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(event)
+		}
+		buf.WriteByte(']')
+		return nil
+	},
+}
+
+// HTTPNDJSON encodes batches as newline-delimited JSON
+var HTTPNDJSON = HTTPBodyFormat{
+	ContentType: "application/x-ndjson",
+	Encode: func(buf *bytes.Buffer, events [][]byte) error {
+		for _, event := range events {
+			buf.Write(event)
+			buf.WriteByte('\n')
+		}
+		return nil
+	},
+}
+
+// HTTPSplunkHEC encodes batches for Splunk's HTTP Event Collector,
+// wrapping each event in an object with an "event" key. The
+// Authorization header has to be set in the writer's Headers.
+var HTTPSplunkHEC = HTTPBodyFormat{
+	ContentType: "application/json",
+	Encode: func(buf *bytes.Buffer, events [][]byte) error {
+		for _, event := range events {
+			buf.WriteString(`{"event":`)
+			buf.Write(event)
+			buf.WriteString("}\n")
+		}
+		return nil
+	},
+}
+
+// HTTPElasticBulk returns a format that encodes batches for
+// Elasticsearch's _bulk API, indexing each event into the
+// given index
+func HTTPElasticBulk(index string) HTTPBodyFormat {
+	action := &bytes.Buffer{}
+	action.WriteString(`{"create":{"_index":`)
+	writeJSONString(action, index)
+	action.WriteString("}}\n")
+
+	return HTTPBodyFormat{
+		ContentType: "application/x-ndjson",
+		Encode: func(buf *bytes.Buffer, events [][]byte) error {
+			for _, event := range events {
+				buf.Write(action.Bytes())
+				buf.Write(event)
+				buf.WriteByte('\n')
+			}
+			return nil
+		},
+	}
+}
+
+// HTTPWriter is an io.Writer that batches events and sends them
+// to an HTTP endpoint. Each call to Write is treated as a single
+// event, which is how the loggers in this package write to
+// their output. It's safe for concurrent use.
+//
+// Batches are sent when they reach BatchSize events or
+// BatchBytes bytes, when the flush interval elapses, or
+// when Flush or Close is called. Failed requests are
+// retried with exponential backoff, respecting the
+// Retry-After header if the server sends one.
+type HTTPWriter struct {
+	HTTPEndpoint
+	// Format controls the body of each request
+	Format HTTPBodyFormat
+
+	Batching
+}
+
+// HTTPEndpoint contains the settings used to send requests
+// to an HTTP endpoint. It's embedded in HTTPWriter, and in
+// the sinks built on it, such as LokiClient and OTLPExporter.
+type HTTPEndpoint struct {
+	// URL is the URL batches are sent to
+	URL string
+	// Client is the HTTP client used to send requests
+	Client *http.Client
+	// Headers are added to every request
+	Headers map[string]string
+	// Compress enables gzip compression of request bodies
+	Compress bool
+}
+
+// NewHTTPWriter creates a new HTTPWriter that sends batches of
+// newline-delimited JSON events to the given URL, and starts a
+// goroutine that sends them in the background every flush interval.
+func NewHTTPWriter(url string, interval time.Duration) *HTTPWriter {
+	hw := &HTTPWriter{
+		HTTPEndpoint: HTTPEndpoint{
+			URL:      url,
+			Client:   http.DefaultClient,
+			Compress: true,
+		},
+		Format: HTTPNDJSON,
+		Batching: Batching{
+			BatchSize:      500,
+			BatchBytes:     1 << 20,
+			MaxPending:     10000,
+			MaxRetries:     5,
+			InitialBackoff: 100 * time.Millisecond,
+			MaxBackoff:     30 * time.Second,
+		},
+	}
+	hw.startBatching(interval, hw.prepare)
+	return hw
+}
+
+// Write adds p to the pending events as a single event. If
+// the buffer is full, the backpressure policy is applied.
+func (hw *HTTPWriter) Write(p []byte) (int, error) {
+	event := bytes.TrimSuffix(p, []byte{'\n'})
+	event = append([]byte(nil), event...)
+	if err := hw.enqueue(event); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// prepare encodes a batch into a request body
+// and returns a function that posts it
+func (hw *HTTPWriter) prepare(batch [][]byte) (batchAttempt, error) {
+	buf := &bytes.Buffer{}
+	if err := hw.Format.Encode(buf, batch); err != nil {
+		return nil, err
+	}
+
+	body := buf.Bytes()
+	if hw.Compress {
+		zbuf := &bytes.Buffer{}
+		zw := gzip.NewWriter(zbuf)
+		zw.Write(body)
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		body = zbuf.Bytes()
+	}
+
+	return func() (time.Duration, bool, error) {
+		return hw.post(body)
+	}, nil
+}
+
+// post sends a single request. It reports whether the request may
+// be retried if it fails, and how long to wait before retrying if
+// the server sent a Retry-After header. Otherwise, wait is negative.
+func (hw *HTTPWriter) post(body []byte) (wait time.Duration, retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, hw.URL, bytes.NewReader(body))
+	if err != nil {
+		return -1, false, err
+	}
+	req.Header.Set("Content-Type", hw.Format.ContentType)
+	if hw.Compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range hw.Headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := hw.Client.Do(req)
+	if err != nil {
+		return -1, true, err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, res.Body)
+
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		return -1, false, nil
+	case res.StatusCode == http.StatusTooManyRequests, res.StatusCode >= 500:
+		return retryAfter(res.Header.Get("Retry-After")), true, fmt.Errorf("http writer: unexpected status: %s", res.Status)
+	default:
+		return -1, false, fmt.Errorf("http writer: unexpected status: %s", res.Status)
+	}
+}
+
+// retryAfter parses the value of a Retry-After header, which may be
+// either a number of seconds or an HTTP date. If the value is empty
+// or invalid, it returns -1.
+func retryAfter(val string) time.Duration {
+	if val == "" {
+		return -1
+	}
+	if secs, err := strconv.Atoi(val); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(val); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+		return 0
+	}
+	return -1
+}
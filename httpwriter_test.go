@@ -0,0 +1,197 @@
+package logger_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go.elara.ws/logger"
+)
+
+// newHTTPWriterTest creates an HTTPWriter that sends to hc
+func newHTTPWriterTest(t *testing.T, hc *httpCollector) *logger.HTTPWriter {
+	t.Helper()
+	hw := logger.NewHTTPWriter(serveCollector(t, hc), time.Hour)
+	startBatchingTest(t, hw, &hw.Batching)
+	return hw
+}
+
+func TestHTTPWriter(t *testing.T) {
+	t.Run("batch", func(t *testing.T) {
+		hc := &httpCollector{}
+		hw := newHTTPWriterTest(t, hc)
+		hw.BatchSize = 2
+
+		jsonlog := logger.NewJSON(hw)
+		for i := 0; i < 5; i++ {
+			jsonlog.Info("Test").Int("i", i).Send()
+		}
+		if err := hw.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		lines := hc.lines()
+		if got, want := len(lines), 5; got != want {
+			t.Fatalf("got: %d events, want: %d", got, want)
+		}
+		if !strings.Contains(lines[4], `"i":4`) {
+			t.Errorf("unexpected event order: %v", lines)
+		}
+		if got, want := len(hc.bodies), 3; got != want {
+			t.Errorf("got: %d requests, want: %d", got, want)
+		}
+		if got, want := hw.Stats().Sent, uint64(5); got != want {
+			t.Errorf("got: %d sent, want: %d", got, want)
+		}
+	})
+
+	t.Run("json-array", func(t *testing.T) {
+		hc := &httpCollector{}
+		hw := newHTTPWriterTest(t, hc)
+		hw.Format = logger.HTTPJSONArray
+		hw.Compress = false
+
+		hw.Write([]byte(`{"a":1}` + "\n"))
+		hw.Write([]byte(`{"a":2}` + "\n"))
+		if err := hw.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := hc.bodies[0], `[{"a":1},{"a":2}]`; got != want {
+			t.Errorf("got: %s, want: %s", got, want)
+		}
+	})
+
+	t.Run("formats", func(t *testing.T) {
+		hc := &httpCollector{}
+		hw := newHTTPWriterTest(t, hc)
+
+		hw.Format = logger.HTTPSplunkHEC
+		hw.Write([]byte(`{"a":1}` + "\n"))
+		hw.Flush()
+
+		hw.Format = logger.HTTPElasticBulk("logs")
+		hw.Write([]byte(`{"a":2}` + "\n"))
+		hw.Flush()
+
+		if got, want := hc.bodies[0], `{"event":{"a":1}}`+"\n"; got != want {
+			t.Errorf("got: %s, want: %s", got, want)
+		}
+		if got, want := hc.bodies[1], `{"create":{"_index":"logs"}}`+"\n"+`{"a":2}`+"\n"; got != want {
+			t.Errorf("got: %s, want: %s", got, want)
+		}
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		hc := &httpCollector{failures: 2, status: http.StatusServiceUnavailable}
+		hw := newHTTPWriterTest(t, hc)
+
+		hw.Write([]byte(`{"a":1}`))
+		if err := hw.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := len(hc.lines()), 1; got != want {
+			t.Errorf("got: %d events, want: %d", got, want)
+		}
+		if got, want := hw.Stats().Retries, uint64(2); got != want {
+			t.Errorf("got: %d retries, want: %d", got, want)
+		}
+	})
+
+	t.Run("retry-after", func(t *testing.T) {
+		hc := &httpCollector{
+			failures: 1,
+			status:   http.StatusTooManyRequests,
+			header:   http.Header{"Retry-After": {"0"}},
+		}
+		hw := newHTTPWriterTest(t, hc)
+		// If Retry-After isn't respected, the test will time out
+		hw.InitialBackoff = time.Hour
+
+		hw.Write([]byte(`{"a":1}`))
+		if err := hw.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(hc.lines()), 1; got != want {
+			t.Errorf("got: %d events, want: %d", got, want)
+		}
+	})
+
+	t.Run("give-up", func(t *testing.T) {
+		hc := &httpCollector{failures: 1, status: http.StatusBadRequest}
+		hw := newHTTPWriterTest(t, hc)
+
+		hw.Write([]byte(`{"a":1}`))
+		if err := hw.Flush(); err == nil {
+			t.Error("expected error")
+		}
+
+		stats := hw.Stats()
+		if got, want := stats.Dropped, uint64(1); got != want {
+			t.Errorf("got: %d dropped, want: %d", got, want)
+		}
+		if got, want := hc.requests, 1; got != want {
+			t.Errorf("got: %d requests, want: %d", got, want)
+		}
+	})
+
+	t.Run("backpressure", func(t *testing.T) {
+		for _, tc := range []struct {
+			name   string
+			policy logger.BackpressurePolicy
+			want   []string
+		}{
+			{"drop-oldest", logger.BackpressureDropOldest, []string{"3", "4"}},
+			{"drop-newest", logger.BackpressureDropNewest, []string{"0", "1"}},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				hc := &httpCollector{}
+				hw := newHTTPWriterTest(t, hc)
+				hw.MaxPending = 2
+				hw.Backpressure = tc.policy
+
+				for _, event := range []string{"0", "1", "2", "3", "4"} {
+					hw.Write([]byte(event))
+				}
+				if got, want := hw.Stats().Dropped, uint64(3); got != want {
+					t.Errorf("got: %d dropped, want: %d", got, want)
+				}
+
+				hw.Flush()
+				if got, want := strings.Join(hc.lines(), ","), strings.Join(tc.want, ","); got != want {
+					t.Errorf("got: %s, want: %s", got, want)
+				}
+			})
+		}
+
+		t.Run("block", func(t *testing.T) {
+			hc := &httpCollector{}
+			hw := newHTTPWriterTest(t, hc)
+			hw.MaxPending = 1
+			hw.Backpressure = logger.BackpressureBlock
+
+			hw.Write([]byte("0"))
+			written := make(chan struct{})
+			go func() {
+				hw.Write([]byte("1"))
+				close(written)
+			}()
+
+			select {
+			case <-written:
+				t.Fatal("expected write to block")
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			hw.Flush()
+			<-written
+			hw.Flush()
+
+			if got, want := strings.Join(hc.lines(), ","), "0,1"; got != want {
+				t.Errorf("got: %s, want: %s", got, want)
+			}
+		})
+	})
+}
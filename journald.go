@@ -0,0 +1,293 @@
+//go:build linux
+
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+var _ Logger = (*JournaldLogger)(nil)
+
+// JournaldSocket is the path to journald's native protocol socket
+const JournaldSocket = "/run/systemd/journal/socket"
+
+// JournaldLogger implements the Logger interface using
+// journald's native protocol, so that fields are indexed
+// by journald rather than being flattened into the message.
+//
+// Field keys are converted to valid journald field names
+// by upper-casing them and replacing invalid characters
+// with underscores.
+type JournaldLogger struct {
+	Level LogLevel
+
+	// SyslogIdentifier is the value of the SYSLOG_IDENTIFIER
+	// field, which is used by journalctl -t
+	SyslogIdentifier string
+
+	conn *net.UnixConn
+	addr *net.UnixAddr
+
+	ErrorHandling
+	Environment
+
+	noPanic bool
+	noExit  bool
+}
+
+// NewJournald creates and returns a new JournaldLogger that
+// sends entries to the given socket, which should usually
+// be JournaldSocket.
+func NewJournald(socket string) (*JournaldLogger, error) {
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &JournaldLogger{
+		Level:            LogLevelInfo,
+		SyslogIdentifier: filepath.Base(os.Args[0]),
+		conn:             conn,
+		addr:             &net.UnixAddr{Name: socket, Net: "unixgram"},
+	}, nil
+}
+
+// Close closes the logger's socket
+func (jl *JournaldLogger) Close() error {
+	return jl.conn.Close()
+}
+
+// NoPanic prevents the logger from panicking on panic events
+func (jl *JournaldLogger) NoPanic() {
+	jl.noPanic = true
+}
+
+// NoExit prevents the logger from exiting on fatal events
+func (jl *JournaldLogger) NoExit() {
+	jl.noExit = true
+}
+
+// SetLevel sets the log level of the logger
+func (jl *JournaldLogger) SetLevel(l LogLevel) {
+	jl.Level = l
+}
+
+// Enabled reports whether events with the given level are logged
+func (jl *JournaldLogger) Enabled(lvl LogLevel) bool {
+	return lvl >= jl.Level
+}
+
+// Debug creates a new debug event with the given message
+func (jl *JournaldLogger) Debug(msg string) LogBuilder {
+	return newJournaldLogBuilder(jl, msg, LogLevelDebug)
+}
+
+// Debugf creates a new debug event with the formatted message
+func (jl *JournaldLogger) Debugf(format string, v ...any) LogBuilder {
+	return newJournaldLogBuilder(jl, fmt.Sprintf(format, v...), LogLevelDebug)
+}
+
+// Info creates a new info event with the given message
+func (jl *JournaldLogger) Info(msg string) LogBuilder {
+	return newJournaldLogBuilder(jl, msg, LogLevelInfo)
+}
+
+// Infof creates a new info event with the formatted message
+func (jl *JournaldLogger) Infof(format string, v ...any) LogBuilder {
+	return newJournaldLogBuilder(jl, fmt.Sprintf(format, v...), LogLevelInfo)
+}
+
+// Warn creates a new warn event with the given message
+func (jl *JournaldLogger) Warn(msg string) LogBuilder {
+	return newJournaldLogBuilder(jl, msg, LogLevelWarn)
+}
+
+// Warnf creates a new warn event with the formatted message
+func (jl *JournaldLogger) Warnf(format string, v ...any) LogBuilder {
+	return newJournaldLogBuilder(jl, fmt.Sprintf(format, v...), LogLevelWarn)
+}
+
+// Error creates a new error event with the given message
+func (jl *JournaldLogger) Error(msg string) LogBuilder {
+	return newJournaldLogBuilder(jl, msg, LogLevelError)
+}
+
+// Errorf creates a new error event with the formatted message
+func (jl *JournaldLogger) Errorf(format string, v ...any) LogBuilder {
+	return newJournaldLogBuilder(jl, fmt.Sprintf(format, v...), LogLevelError)
+}
+
+// Fatal creates a new fatal event with the given message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (jl *JournaldLogger) Fatal(msg string) LogBuilder {
+	return newJournaldLogBuilder(jl, msg, LogLevelFatal)
+}
+
+// Fatalf creates a new fatal event with the formatted message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (jl *JournaldLogger) Fatalf(format string, v ...any) LogBuilder {
+	return newJournaldLogBuilder(jl, fmt.Sprintf(format, v...), LogLevelFatal)
+}
+
+// Panic creates a new panic event with the given message
+//
+// When sent, panic events will cause a panic
+func (jl *JournaldLogger) Panic(msg string) LogBuilder {
+	return newJournaldLogBuilder(jl, msg, LogLevelPanic)
+}
+
+// Panicf creates a new panic event with the formatted message
+//
+// When sent, panic events will cause a panic
+func (jl *JournaldLogger) Panicf(format string, v ...any) LogBuilder {
+	return newJournaldLogBuilder(jl, fmt.Sprintf(format, v...), LogLevelPanic)
+}
+
+func newJournaldLogBuilder(jl *JournaldLogger, msg string, lvl LogLevel) LogBuilder {
+	if !jl.Enabled(lvl) {
+		return NopLogBuilder{}
+	}
+	return newEventLogBuilder(lvl, msg, jl.Now, jl.send)
+}
+
+// LogEvent logs a collected event, keeping its time
+func (jl *JournaldLogger) LogEvent(e *Event) {
+	if jl.Enabled(e.Level) {
+		jl.send(e)
+	}
+}
+
+// send encodes the event as a journal entry and sends it
+func (jl *JournaldLogger) send(e *Event) {
+	buf := &bytes.Buffer{}
+	writeJournalField(buf, "MESSAGE", e.Message)
+	writeJournalField(buf, "PRIORITY", strconv.Itoa(syslogSeverities[e.Level]))
+	if jl.SyslogIdentifier != "" {
+		writeJournalField(buf, "SYSLOG_IDENTIFIER", jl.SyslogIdentifier)
+	}
+	for _, f := range e.Fields {
+		writeJournalField(buf, journalFieldName(f.Key), f.ValueString())
+	}
+	if err := jl.writeEntry(buf.Bytes()); err != nil {
+		jl.handleWriteError(err, buf.Bytes())
+	}
+
+	if e.Level == LogLevelFatal && !jl.noExit {
+		jl.exit()
+	} else if e.Level == LogLevelPanic && !jl.noPanic {
+		jl.doPanic(NewPanicEvent(e))
+	}
+}
+
+// writeEntry sends an encoded entry to journald. If the entry is
+// too large to fit in a datagram, it's written to a memfd
+// or temporary file, and the file descriptor is sent instead.
+func (jl *JournaldLogger) writeEntry(data []byte) error {
+	_, _, err := jl.conn.WriteMsgUnix(data, nil, jl.addr)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, syscall.EMSGSIZE) && !errors.Is(err, syscall.ENOBUFS) {
+		return err
+	}
+
+	f, err := journalFile(data)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, _, err = jl.conn.WriteMsgUnix(nil, unix.UnixRights(int(f.Fd())), jl.addr)
+	return err
+}
+
+// journalFile returns a sealed memfd containing data. If memfd
+// isn't supported, an unlinked temporary file is used instead.
+func journalFile(data []byte) (*os.File, error) {
+	fd, err := unix.MemfdCreate("logger-journal", unix.MFD_ALLOW_SEALING|unix.MFD_CLOEXEC)
+	if err != nil {
+		return journalTempFile(data)
+	}
+	f := os.NewFile(uintptr(fd), "logger-journal")
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return nil, err
+	}
+	_, err = unix.FcntlInt(f.Fd(), unix.F_ADD_SEALS, unix.F_SEAL_SHRINK|unix.F_SEAL_GROW|unix.F_SEAL_WRITE|unix.F_SEAL_SEAL)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// journalTempFile returns an unlinked temporary file in
+// /dev/shm containing data
+func journalTempFile(data []byte) (*os.File, error) {
+	f, err := os.CreateTemp("/dev/shm", "logger-journal-")
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// writeJournalField writes a field to the buffer using the
+// native protocol. Values containing newlines are written
+// using the binary format, with an explicit length.
+func writeJournalField(buf *bytes.Buffer, name, val string) {
+	buf.WriteString(name)
+	if !strings.ContainsRune(val, '\n') {
+		buf.WriteByte('=')
+		buf.WriteString(val)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteByte('\n')
+	binary.Write(buf, binary.LittleEndian, uint64(len(val)))
+	buf.WriteString(val)
+	buf.WriteByte('\n')
+}
+
+// journalFieldName converts a key to a valid journald field name.
+// Names may only contain uppercase letters, digits and underscores,
+// and may not begin with a digit or underscore.
+func journalFieldName(key string) string {
+	name := make([]byte, 0, len(key))
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			name = append(name, c-'a'+'A')
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			name = append(name, c)
+		default:
+			name = append(name, '_')
+		}
+	}
+
+	name = bytes.TrimLeft(name, "_")
+	if len(name) == 0 || (name[0] >= '0' && name[0] <= '9') {
+		name = append([]byte("X_"), name...)
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return string(name)
+}
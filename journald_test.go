@@ -0,0 +1,127 @@
+//go:build linux
+
+package logger_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.elara.ws/logger"
+	"golang.org/x/sys/unix"
+)
+
+// readJournal reads a single entry from a journald stand-in
+// socket, following file descriptors for large entries,
+// and decodes its fields
+func readJournal(t *testing.T, conn *net.UnixConn) map[string]string {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	buf := make([]byte, 65536)
+	oob := make([]byte, unix.CmsgSpace(4))
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := buf[:n]
+
+	if oobn > 0 {
+		msgs, err := unix.ParseSocketControlMessage(oob[:oobn])
+		if err != nil {
+			t.Fatal(err)
+		}
+		fds, err := unix.ParseUnixRights(&msgs[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := os.NewFile(uintptr(fds[0]), "journal")
+		defer f.Close()
+		data, err = io.ReadAll(io.NewSectionReader(f, 0, 1<<30))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fields := map[string]string{}
+	for len(data) > 0 {
+		i := bytes.IndexAny(data, "=\n")
+		if i < 0 {
+			t.Fatalf("invalid entry: %q", data)
+		}
+		name := string(data[:i])
+		if data[i] == '=' {
+			end := bytes.IndexByte(data, '\n')
+			fields[name] = string(data[i+1 : end])
+			data = data[end+1:]
+			continue
+		}
+		size := binary.LittleEndian.Uint64(data[i+1 : i+9])
+		fields[name] = string(data[i+9 : i+9+int(size)])
+		data = data[i+9+int(size)+1:]
+	}
+	return fields
+}
+
+func newJournaldTest(t *testing.T) (*net.UnixConn, *logger.JournaldLogger) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	jl, err := logger.NewJournald(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { jl.Close() })
+	jl.SyslogIdentifier = "test"
+	return conn, jl
+}
+
+func TestJournald(t *testing.T) {
+	t.Run("fields", func(t *testing.T) {
+		conn, jl := newJournaldTest(t)
+		jl.Warn("Test").
+			Int("n", 1234).
+			Str("request.id", "abc").
+			Str("_trusted", "x").
+			Str("multi", "line one\nline two").
+			Send()
+
+		fields := readJournal(t, conn)
+		want := map[string]string{
+			"MESSAGE":           "Test",
+			"PRIORITY":          "4",
+			"SYSLOG_IDENTIFIER": "test",
+			"N":                 "1234",
+			"REQUEST_ID":        "abc",
+			"TRUSTED":           "x",
+			"MULTI":             "line one\nline two",
+		}
+		for k, v := range want {
+			if fields[k] != v {
+				t.Errorf("%s: got: %q, want: %q", k, fields[k], v)
+			}
+		}
+	})
+
+	t.Run("large", func(t *testing.T) {
+		conn, jl := newJournaldTest(t)
+		long := strings.Repeat("a", 4<<20)
+		jl.Info(long).Send()
+
+		fields := readJournal(t, conn)
+		if fields["MESSAGE"] != long {
+			t.Errorf("expected message of length %d, got %d", len(long), len(fields["MESSAGE"]))
+		}
+	})
+}
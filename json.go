@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"os"
 	"strconv"
 	"time"
 )
@@ -19,6 +18,10 @@ type JSONLogger struct {
 	Out   io.Writer
 	Level LogLevel
 
+	ErrorHandling
+	Environment
+	Hooks
+
 	noPanic bool
 	noExit  bool
 }
@@ -45,6 +48,13 @@ func (jl *JSONLogger) SetLevel(l LogLevel) {
 	jl.Level = l
 }
 
+// Enabled reports whether events with the given level are logged.
+// If any hooks have been added, every level is enabled, since the
+// hooks may change the level of events.
+func (jl *JSONLogger) Enabled(lvl LogLevel) bool {
+	return jl.Out != io.Discard && (lvl >= jl.Level || jl.hooked())
+}
+
 // Debug creates a new debug event with the given message
 func (jl *JSONLogger) Debug(msg string) LogBuilder {
 	return newJSONLogBuilder(jl, msg, LogLevelDebug)
@@ -119,22 +129,56 @@ type JSONLogBuilder struct {
 	l   *JSONLogger
 	lvl LogLevel
 	out writer
+	pe  *PanicEvent
+	now func() time.Time
 }
 
 func newJSONLogBuilder(jl *JSONLogger, msg string, lvl LogLevel) LogBuilder {
-	if jl.Out == io.Discard || lvl < jl.Level {
+	return newJSONLogBuilderClock(jl, msg, lvl, jl.Now)
+}
+
+// newJSONLogBuilderClock creates a new builder for an
+// event whose time is returned by now
+func newJSONLogBuilderClock(jl *JSONLogger, msg string, lvl LogLevel, now func() time.Time) LogBuilder {
+	if !jl.Enabled(lvl) {
 		return NopLogBuilder{}
 	}
+	if jl.hooked() {
+		return jl.withHooks(lvl, msg, now, func(lvl LogLevel, msg string) LogBuilder {
+			// The level is checked after the hooks have run,
+			// since they may have changed it
+			if lvl < jl.Level {
+				return NopLogBuilder{}
+			}
+			return startJSONLogBuilder(jl, msg, lvl, now)
+		})
+	}
+	return startJSONLogBuilder(jl, msg, lvl, now)
+}
+
+// LogEvent logs a collected event, keeping its time
+func (jl *JSONLogger) LogEvent(e *Event) {
+	now := func() time.Time { return e.Time }
+	e.Apply(newJSONLogBuilderClock(jl, e.Message, e.Level, now)).Send()
+}
+
+// startJSONLogBuilder creates a new builder and
+// writes the beginning of the event to it
+func startJSONLogBuilder(jl *JSONLogger, msg string, lvl LogLevel, now func() time.Time) LogBuilder {
 	lb := &JSONLogBuilder{
 		out: writer{&bytes.Buffer{}, jl.Out},
 		lvl: lvl,
 		l:   jl,
+		now: now,
 	}
 	lb.out.WriteString(`{"msg":"`)
 	lb.out.WriteString(msg)
 	lb.out.WriteString(`","level":"`)
-	lb.out.WriteString(logLevelNames[lvl])
+	lb.out.WriteString(lvl.String())
 	lb.out.WriteByte('"')
+	if lvl == LogLevelPanic && !jl.noPanic {
+		return capturePanic(lb, msg, now, &lb.pe)
+	}
 	return lb
 }
 
@@ -145,6 +189,68 @@ func (jlb *JSONLogBuilder) writeKey(k string) {
 	jlb.out.WriteString(`":`)
 }
 
+// writeJSONString writes a quoted and escaped JSON string to the buffer
+func writeJSONString(buf *bytes.Buffer, s string) {
+	const hexDigits = "0123456789abcdef"
+	buf.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 0x20 && c != '"' && c != '\\' {
+			continue
+		}
+		buf.WriteString(s[start:i])
+		switch c {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			buf.WriteString(`\u00`)
+			buf.WriteByte(hexDigits[c>>4])
+			buf.WriteByte(hexDigits[c&0xF])
+		}
+		start = i + 1
+	}
+	buf.WriteString(s[start:])
+	buf.WriteByte('"')
+}
+
+// writeJSONValue writes the value of a field to the buffer as JSON
+func writeJSONValue(buf *bytes.Buffer, f Field) {
+	switch f.Kind {
+	case KindInt:
+		buf.WriteString(strconv.FormatInt(f.Value.(int64), 10))
+	case KindUint:
+		buf.WriteString(strconv.FormatUint(f.Value.(uint64), 10))
+	case KindFloat32:
+		buf.WriteString(strconv.FormatFloat(float64(f.Value.(float32)), 'f', -1, 32))
+	case KindFloat64:
+		buf.WriteString(strconv.FormatFloat(f.Value.(float64), 'f', -1, 64))
+	case KindBool:
+		buf.WriteString(strconv.FormatBool(f.Value.(bool)))
+	case KindString:
+		writeJSONString(buf, f.Value.(string))
+	case KindBytes:
+		writeJSONString(buf, base64.StdEncoding.EncodeToString(f.Value.([]byte)))
+	case KindTime:
+		writeJSONString(buf, f.Value.(time.Time).Format(time.RFC3339Nano))
+	case KindError:
+		writeJSONString(buf, f.Value.(error).Error())
+	default:
+		data, err := json.Marshal(f.Value)
+		if err != nil {
+			panic(err)
+		}
+		buf.Write(data)
+	}
+}
+
 // Int adds an int field to the output
 func (jlb *JSONLogBuilder) Int(key string, val int) LogBuilder {
 	return jlb.Int64(key, int64(val))
@@ -230,7 +336,7 @@ func (jlb *JSONLogBuilder) Bytes(key string, b []byte) LogBuilder {
 // Timestamp adds the time formatted as RFC3339Nano
 // as a field to the output using the key "timestamp"
 func (jlb *JSONLogBuilder) Timestamp() LogBuilder {
-	return jlb.Str("timestamp", time.Now().Format(time.RFC3339Nano))
+	return jlb.Str("timestamp", jlb.now().Format(time.RFC3339Nano))
 }
 
 // Bool adds a bool as a field to the output
@@ -276,10 +382,10 @@ func (jlb *JSONLogBuilder) Err(err error) LogBuilder {
 // After calling send, do not use the event again.
 func (jlb *JSONLogBuilder) Send() {
 	jlb.out.WriteByte('}')
-	jlb.out.Flush()
+	jlb.l.flush(jlb.out)
 	if jlb.lvl == LogLevelFatal && !jlb.l.noExit {
-		os.Exit(1)
+		jlb.l.exit()
 	} else if jlb.lvl == LogLevelPanic && !jlb.l.noPanic {
-		panic("")
+		jlb.l.doPanic(jlb.pe)
 	}
 }
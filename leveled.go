@@ -0,0 +1,168 @@
+package logger
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+var _ Logger = (*LeveledLogger)(nil)
+
+// LeveledLogger implements the Logger interface by passing
+// events at or above its level to an underlying logger. Unlike
+// most loggers, its level is stored atomically, so SetLevel
+// can safely be called while events are being logged, such
+// as by a LevelHandler.
+type LeveledLogger struct {
+	Logger Logger
+
+	level uint32
+}
+
+// NewLeveled creates and returns a new LeveledLogger wrapping
+// l with the given level. The level of l is set to debug,
+// so that it doesn't filter out any of the passed events.
+func NewLeveled(l Logger, lvl LogLevel) *LeveledLogger {
+	l.SetLevel(LogLevelDebug)
+	return &LeveledLogger{Logger: l, level: uint32(lvl)}
+}
+
+// Level returns the current level of the logger
+func (ll *LeveledLogger) Level() LogLevel {
+	return LogLevel(atomic.LoadUint32(&ll.level))
+}
+
+// Enabled reports whether events with the given level are logged
+func (ll *LeveledLogger) Enabled(lvl LogLevel) bool {
+	return lvl >= ll.Level() && enabled(ll.Logger, lvl)
+}
+
+// NoPanic prevents the logger from panicking on panic events
+func (ll *LeveledLogger) NoPanic() {
+	ll.Logger.NoPanic()
+}
+
+// NoExit prevents the logger from exiting on fatal events
+func (ll *LeveledLogger) NoExit() {
+	ll.Logger.NoExit()
+}
+
+// Now returns the current time using
+// the clock of the underlying logger
+func (ll *LeveledLogger) Now() time.Time {
+	return clockOf(ll.Logger)()
+}
+
+// SetLevel sets the log level of the logger
+func (ll *LeveledLogger) SetLevel(l LogLevel) {
+	atomic.StoreUint32(&ll.level, uint32(l))
+}
+
+// compareAndSwapLevel sets the level to new
+// only if it's currently old
+func (ll *LeveledLogger) compareAndSwapLevel(old, new LogLevel) bool {
+	return atomic.CompareAndSwapUint32(&ll.level, uint32(old), uint32(new))
+}
+
+// Debug creates a new debug event with the given message
+func (ll *LeveledLogger) Debug(msg string) LogBuilder {
+	if !ll.Enabled(LogLevelDebug) {
+		return NopLogBuilder{}
+	}
+	return ll.Logger.Debug(msg)
+}
+
+// Debugf creates a new debug event with the formatted message
+func (ll *LeveledLogger) Debugf(format string, v ...any) LogBuilder {
+	if !ll.Enabled(LogLevelDebug) {
+		return NopLogBuilder{}
+	}
+	return ll.Logger.Debugf(format, v...)
+}
+
+// Info creates a new info event with the given message
+func (ll *LeveledLogger) Info(msg string) LogBuilder {
+	if !ll.Enabled(LogLevelInfo) {
+		return NopLogBuilder{}
+	}
+	return ll.Logger.Info(msg)
+}
+
+// Infof creates a new info event with the formatted message
+func (ll *LeveledLogger) Infof(format string, v ...any) LogBuilder {
+	if !ll.Enabled(LogLevelInfo) {
+		return NopLogBuilder{}
+	}
+	return ll.Logger.Infof(format, v...)
+}
+
+// Warn creates a new warn event with the given message
+func (ll *LeveledLogger) Warn(msg string) LogBuilder {
+	if !ll.Enabled(LogLevelWarn) {
+		return NopLogBuilder{}
+	}
+	return ll.Logger.Warn(msg)
+}
+
+// Warnf creates a new warn event with the formatted message
+func (ll *LeveledLogger) Warnf(format string, v ...any) LogBuilder {
+	if !ll.Enabled(LogLevelWarn) {
+		return NopLogBuilder{}
+	}
+	return ll.Logger.Warnf(format, v...)
+}
+
+// Error creates a new error event with the given message
+func (ll *LeveledLogger) Error(msg string) LogBuilder {
+	if !ll.Enabled(LogLevelError) {
+		return NopLogBuilder{}
+	}
+	return ll.Logger.Error(msg)
+}
+
+// Errorf creates a new error event with the formatted message
+func (ll *LeveledLogger) Errorf(format string, v ...any) LogBuilder {
+	if !ll.Enabled(LogLevelError) {
+		return NopLogBuilder{}
+	}
+	return ll.Logger.Errorf(format, v...)
+}
+
+// Fatal creates a new fatal event with the given message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (ll *LeveledLogger) Fatal(msg string) LogBuilder {
+	if !ll.Enabled(LogLevelFatal) {
+		return NopLogBuilder{}
+	}
+	return ll.Logger.Fatal(msg)
+}
+
+// Fatalf creates a new fatal event with the formatted message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (ll *LeveledLogger) Fatalf(format string, v ...any) LogBuilder {
+	if !ll.Enabled(LogLevelFatal) {
+		return NopLogBuilder{}
+	}
+	return ll.Logger.Fatalf(format, v...)
+}
+
+// Panic creates a new panic event with the given message
+//
+// When sent, panic events will cause a panic
+func (ll *LeveledLogger) Panic(msg string) LogBuilder {
+	if !ll.Enabled(LogLevelPanic) {
+		return NopLogBuilder{}
+	}
+	return ll.Logger.Panic(msg)
+}
+
+// Panicf creates a new panic event with the formatted message
+//
+// When sent, panic events will cause a panic
+func (ll *LeveledLogger) Panicf(format string, v ...any) LogBuilder {
+	if !ll.Enabled(LogLevelPanic) {
+		return NopLogBuilder{}
+	}
+	return ll.Logger.Panicf(format, v...)
+}
@@ -0,0 +1,270 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var _ http.Handler = (*LevelHandler)(nil)
+
+// ErrNoSuchLogger is returned when a LevelHandler
+// has no logger registered with the given name
+var ErrNoSuchLogger = errors.New("no such logger")
+
+// LevelController is implemented by loggers whose level can be
+// read and changed at runtime, such as LeveledLogger and NamedLogger
+type LevelController interface {
+	Level() LogLevel
+	SetLevel(LogLevel)
+}
+
+// levelSwapper is implemented by loggers that can change their
+// level atomically if it still has the expected value, such
+// as LeveledLogger and NamedLogger
+type levelSwapper interface {
+	compareAndSwapLevel(old, new LogLevel) bool
+}
+
+// levelResetter is implemented by loggers whose level is resolved
+// from rules unless it's been set explicitly, such as NamedLogger
+type levelResetter interface {
+	// levelSet reports whether the level was set explicitly
+	levelSet() bool
+	// compareAndResetLevel makes the level follow the rules
+	// again only if it's currently set to old
+	compareAndResetLevel(old LogLevel) bool
+}
+
+// maxLevelRequestSize is the maximum size of a PUT request body
+const maxLevelRequestSize = 1 << 10
+
+// LevelHandler is an http.Handler that reads and changes the
+// levels of loggers at runtime, such as to temporarily enable
+// debug events on a live instance. The global logger is
+// registered with an empty name.
+//
+// GET returns the level of the logger named by the "logger" query
+// parameter. If the parameter is absent, the global logger's level
+// is returned, along with the levels of all the named loggers.
+//
+// PUT sets the level of the logger named by the "logger" query
+// parameter, using a JSON body such as {"level":"debug","ttl":"10m"},
+// which may be at most 1 KiB.
+// If a TTL is given, the level reverts when it expires, unless the
+// level has been changed in the meantime.
+type LevelHandler struct {
+	mu      sync.Mutex
+	loggers map[string]*levelEntry
+}
+
+// levelEntry is a logger registered with a LevelHandler
+type levelEntry struct {
+	l LevelController
+
+	// base is the level to revert to, and temp is the level
+	// set with a TTL, which expires at the given time. If
+	// baseRules is set, the base level was resolved from the
+	// logger's rules, so reverting resets the level instead.
+	base      LogLevel
+	baseRules bool
+	temp      LogLevel
+	expires   time.Time
+	timer     *time.Timer
+}
+
+// levelState is the JSON representation of a logger's level
+type levelState struct {
+	Level   string                 `json:"level,omitempty"`
+	Expires *time.Time             `json:"expires,omitempty"`
+	Loggers map[string]*levelState `json:"loggers,omitempty"`
+}
+
+// levelRequest is the JSON body of a PUT request
+type levelRequest struct {
+	Level string `json:"level"`
+	TTL   string `json:"ttl"`
+}
+
+// NewLevelHandler creates and returns a new LevelHandler
+// with the given global logger, which may be nil
+func NewLevelHandler(global LevelController) *LevelHandler {
+	lh := &LevelHandler{loggers: map[string]*levelEntry{}}
+	if global != nil {
+		lh.Register("", global)
+	}
+	return lh
+}
+
+// Register registers l with the given name, replacing
+// any logger that was already registered with it
+func (lh *LevelHandler) Register(name string, l LevelController) {
+	lh.mu.Lock()
+	defer lh.mu.Unlock()
+	if old, ok := lh.loggers[name]; ok && old.timer != nil {
+		old.timer.Stop()
+	}
+	lh.loggers[name] = &levelEntry{l: l}
+}
+
+// Level returns the current level of the named logger
+func (lh *LevelHandler) Level(name string) (LogLevel, error) {
+	lh.mu.Lock()
+	defer lh.mu.Unlock()
+	entry, ok := lh.loggers[name]
+	if !ok {
+		return 0, ErrNoSuchLogger
+	}
+	return entry.l.Level(), nil
+}
+
+// SetLevel sets the level of the named logger. If ttl is greater
+// than zero, the level reverts to its previous value once ttl has
+// elapsed. Setting a level while a previous TTL is pending keeps
+// the original level as the one to revert to.
+func (lh *LevelHandler) SetLevel(name string, lvl LogLevel, ttl time.Duration) error {
+	lh.mu.Lock()
+	defer lh.mu.Unlock()
+
+	entry, ok := lh.loggers[name]
+	if !ok {
+		return ErrNoSuchLogger
+	}
+
+	pending := entry.timer != nil
+	if pending {
+		entry.timer.Stop()
+		entry.timer = nil
+		entry.expires = time.Time{}
+	}
+
+	if ttl <= 0 {
+		entry.l.SetLevel(lvl)
+		return nil
+	}
+
+	// If the level was changed directly since the TTL
+	// was set, revert to that level instead
+	if !pending || entry.l.Level() != entry.temp {
+		entry.base = entry.l.Level()
+		lr, ok := entry.l.(levelResetter)
+		entry.baseRules = ok && !lr.levelSet()
+	}
+	entry.temp = lvl
+	entry.expires = time.Now().Add(ttl)
+	entry.l.SetLevel(lvl)
+
+	var timer *time.Timer
+	timer = time.AfterFunc(ttl, func() {
+		lh.mu.Lock()
+		defer lh.mu.Unlock()
+		if entry.timer != timer {
+			return
+		}
+		entry.timer = nil
+		entry.expires = time.Time{}
+		entry.revert()
+	})
+	entry.timer = timer
+	return nil
+}
+
+// ServeHTTP handles GET and PUT requests for logger levels
+func (lh *LevelHandler) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	name := req.URL.Query().Get("logger")
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPut:
+		req.Body = http.MaxBytesReader(res, req.Body, maxLevelRequestSize)
+		var body levelRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			http.Error(res, "invalid request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		lvl, err := ParseLogLevel(body.Level)
+		if err != nil {
+			http.Error(res, err.Error()+": "+body.Level, http.StatusBadRequest)
+			return
+		}
+		var ttl time.Duration
+		if body.TTL != "" {
+			ttl, err = time.ParseDuration(body.TTL)
+			if err != nil || ttl < 0 {
+				http.Error(res, "invalid ttl: "+body.TTL, http.StatusBadRequest)
+				return
+			}
+		}
+		if err := lh.SetLevel(name, lvl, ttl); err != nil {
+			http.Error(res, err.Error()+": "+name, http.StatusNotFound)
+			return
+		}
+	default:
+		res.Header().Set("Allow", "GET, HEAD, PUT")
+		http.Error(res, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	state, err := lh.state(name, !req.URL.Query().Has("logger"))
+	if err != nil {
+		http.Error(res, err.Error()+": "+name, http.StatusNotFound)
+		return
+	}
+	res.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(res).Encode(state)
+}
+
+// state returns the state of the named logger. If all is
+// set, the states of the other loggers are included.
+func (lh *LevelHandler) state(name string, all bool) (*levelState, error) {
+	lh.mu.Lock()
+	defer lh.mu.Unlock()
+
+	state := &levelState{}
+	if entry, ok := lh.loggers[name]; ok {
+		state = entry.state()
+	} else if !all {
+		return nil, ErrNoSuchLogger
+	}
+
+	if all {
+		for n, entry := range lh.loggers {
+			if n == name {
+				continue
+			}
+			if state.Loggers == nil {
+				state.Loggers = map[string]*levelState{}
+			}
+			state.Loggers[n] = entry.state()
+		}
+	}
+	return state, nil
+}
+
+// revert sets the level back to the base level, or back to
+// following the logger's rules if that's where the base level
+// came from, unless it's been changed since the temporary
+// level was set. The
+// handler's mutex must be held.
+func (entry *levelEntry) revert() {
+	if lr, ok := entry.l.(levelResetter); ok && entry.baseRules {
+		lr.compareAndResetLevel(entry.temp)
+	} else if ls, ok := entry.l.(levelSwapper); ok {
+		ls.compareAndSwapLevel(entry.temp, entry.base)
+	} else if entry.l.Level() == entry.temp {
+		entry.l.SetLevel(entry.base)
+	}
+}
+
+// state returns the state of the entry.
+// The handler's mutex must be held.
+func (entry *levelEntry) state() *levelState {
+	state := &levelState{Level: entry.l.Level().String()}
+	if entry.timer != nil {
+		expires := entry.expires
+		state.Expires = &expires
+	}
+	return state
+}
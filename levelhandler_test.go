@@ -0,0 +1,212 @@
+package logger_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.elara.ws/logger"
+	"go.elara.ws/logger/logtest"
+)
+
+// doLevelRequest sends a request to the handler and
+// returns the status code and trimmed body
+func doLevelRequest(lh *logger.LevelHandler, method, target, body string) (int, string) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	lh.ServeHTTP(rec, req)
+	return rec.Code, strings.TrimSpace(rec.Body.String())
+}
+
+func TestLeveled(t *testing.T) {
+	o := logtest.New()
+	ll := logger.NewLeveled(o, logger.LogLevelInfo)
+
+	ll.Debug("Test 1").Send()
+	ll.SetLevel(logger.LogLevelDebug)
+	ll.Debugf("Test %d", 2).Send()
+
+	o.AssertNotLogged(t, logger.LogLevelDebug, "Test 1")
+	o.AssertLogged(t, logger.LogLevelDebug, "Test 2")
+	if got, want := ll.Level(), logger.LogLevelDebug; got != want {
+		t.Errorf("got: %d, want: %d", got, want)
+	}
+
+	t.Run("concurrent", func(t *testing.T) {
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				ll.Info("Test").Send()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				ll.SetLevel(logger.LogLevel(i % 3))
+			}
+		}()
+		wg.Wait()
+	})
+}
+
+func TestLevelHandler(t *testing.T) {
+	t.Run("get", func(t *testing.T) {
+		lh := logger.NewLevelHandler(logger.NewLeveled(logtest.New(), logger.LogLevelInfo))
+		lh.Register("db", logger.NewLeveled(logtest.New(), logger.LogLevelWarn))
+
+		code, body := doLevelRequest(lh, http.MethodGet, "/", "")
+		if expected := `{"level":"info","loggers":{"db":{"level":"warn"}}}`; code != http.StatusOK || body != expected {
+			t.Errorf("got: %d %s, want: 200 %s", code, body, expected)
+		}
+
+		code, body = doLevelRequest(lh, http.MethodGet, "/?logger=db", "")
+		if expected := `{"level":"warn"}`; code != http.StatusOK || body != expected {
+			t.Errorf("got: %d %s, want: 200 %s", code, body, expected)
+		}
+
+		code, _ = doLevelRequest(lh, http.MethodGet, "/?logger=http", "")
+		if code != http.StatusNotFound {
+			t.Errorf("got: %d, want: %d", code, http.StatusNotFound)
+		}
+	})
+
+	t.Run("put", func(t *testing.T) {
+		o := logtest.New()
+		db := logger.NewLeveled(o, logger.LogLevelInfo)
+		lh := logger.NewLevelHandler(nil)
+		lh.Register("db", db)
+
+		code, body := doLevelRequest(lh, http.MethodPut, "/?logger=db", `{"level":"debug"}`)
+		if expected := `{"level":"debug"}`; code != http.StatusOK || body != expected {
+			t.Errorf("got: %d %s, want: 200 %s", code, body, expected)
+		}
+
+		db.Debug("Test").Send()
+		o.AssertLogged(t, logger.LogLevelDebug, "Test")
+	})
+
+	t.Run("named", func(t *testing.T) {
+		o := logtest.New()
+		db := logger.NewNamed(o, logger.LevelRules{Default: logger.LogLevelInfo}).Named("db")
+		lh := logger.NewLevelHandler(nil)
+		lh.Register("db", db)
+
+		code, body := doLevelRequest(lh, http.MethodPut, "/?logger=db", `{"level":"debug","ttl":"50ms"}`)
+		if code != http.StatusOK || !strings.Contains(body, `"level":"debug"`) {
+			t.Errorf("got: %d %s, want: 200 with debug level", code, body)
+		}
+
+		db.Debug("Test").Send()
+		o.AssertLogged(t, logger.LogLevelDebug, "Test")
+
+		waitForLevel(t, db, logger.LogLevelInfo)
+	})
+
+	t.Run("named-rules", func(t *testing.T) {
+		root := logger.NewNamed(logtest.New(), logger.LevelRules{Levels: map[string]logger.LogLevel{"db": logger.LogLevelWarn}})
+		db := root.Named("db")
+		lh := logger.NewLevelHandler(nil)
+		lh.Register("db", db)
+
+		lh.SetLevel("db", logger.LogLevelDebug, 20*time.Millisecond)
+		waitForLevel(t, db, logger.LogLevelWarn)
+
+		root.SetRules(logger.LevelRules{Levels: map[string]logger.LogLevel{"db": logger.LogLevelError}})
+		if got, want := db.Level(), logger.LogLevelError; got != want {
+			t.Errorf("got: %d, want: %d", got, want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		lh := logger.NewLevelHandler(logger.NewLeveled(logtest.New(), logger.LogLevelInfo))
+
+		tests := []struct {
+			method, body string
+			code         int
+		}{
+			{http.MethodPut, `{"level":"verbose"}`, http.StatusBadRequest},
+			{http.MethodPut, `{"level":"debug","ttl":"soon"}`, http.StatusBadRequest},
+			{http.MethodPut, `{"level":`, http.StatusBadRequest},
+			{http.MethodPut, strings.Repeat(" ", 2048) + `{"level":"debug"}`, http.StatusBadRequest},
+			{http.MethodPost, `{"level":"debug"}`, http.StatusMethodNotAllowed},
+		}
+		for _, test := range tests {
+			code, body := doLevelRequest(lh, test.method, "/", test.body)
+			if code != test.code {
+				t.Errorf("%s %.32q: got: %d %s, want: %d", test.method, test.body, code, body, test.code)
+			}
+		}
+
+		if lvl, _ := lh.Level(""); lvl != logger.LogLevelInfo {
+			t.Errorf("got: %d, want: %d", lvl, logger.LogLevelInfo)
+		}
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		ll := logger.NewLeveled(logtest.New(), logger.LogLevelInfo)
+		lh := logger.NewLevelHandler(ll)
+
+		code, body := doLevelRequest(lh, http.MethodPut, "/", `{"level":"debug","ttl":"50ms"}`)
+		if code != http.StatusOK || !strings.Contains(body, `"expires":`) {
+			t.Errorf("got: %d %s, want: 200 with expiry", code, body)
+		}
+		if got, want := ll.Level(), logger.LogLevelDebug; got != want {
+			t.Errorf("got: %d, want: %d", got, want)
+		}
+
+		waitForLevel(t, ll, logger.LogLevelInfo)
+
+		_, body = doLevelRequest(lh, http.MethodGet, "/", "")
+		if expected := `{"level":"info"}`; body != expected {
+			t.Errorf("got: %s, want: %s", body, expected)
+		}
+	})
+
+	t.Run("ttl-stacked", func(t *testing.T) {
+		ll := logger.NewLeveled(logtest.New(), logger.LogLevelInfo)
+		lh := logger.NewLevelHandler(ll)
+
+		lh.SetLevel("", logger.LogLevelWarn, time.Hour)
+		lh.SetLevel("", logger.LogLevelDebug, 50*time.Millisecond)
+
+		waitForLevel(t, ll, logger.LogLevelInfo)
+	})
+
+	t.Run("ttl-changed", func(t *testing.T) {
+		ll := logger.NewLeveled(logtest.New(), logger.LogLevelInfo)
+		lh := logger.NewLevelHandler(ll)
+
+		lh.SetLevel("", logger.LogLevelDebug, 20*time.Millisecond)
+		ll.SetLevel(logger.LogLevelError)
+		time.Sleep(100 * time.Millisecond)
+
+		if got, want := ll.Level(), logger.LogLevelError; got != want {
+			t.Errorf("got: %d, want: %d", got, want)
+		}
+	})
+
+	t.Run("no-such-logger", func(t *testing.T) {
+		lh := logger.NewLevelHandler(nil)
+		if err := lh.SetLevel("db", logger.LogLevelDebug, 0); !errors.Is(err, logger.ErrNoSuchLogger) {
+			t.Errorf("got: %v, want: %v", err, logger.ErrNoSuchLogger)
+		}
+	})
+}
+
+// waitForLevel waits until ll has the given level
+func waitForLevel(t *testing.T, ll logger.LevelController, lvl logger.LogLevel) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for ll.Level() != lvl {
+		if time.Now().After(deadline) {
+			t.Fatalf("got: %d, want: %d", ll.Level(), lvl)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -82,3 +82,9 @@ func Panic(msg string) logger.LogBuilder {
 func Panicf(format string, v ...any) logger.LogBuilder {
 	return Logger.Panicf(format, v...)
 }
+
+// RegisterExitHandler adds a function that's called before a fatal
+// event ends the program. The returned function unregisters it.
+func RegisterExitHandler(fn func()) (unregister func()) {
+	return logger.RegisterExitHandler(fn)
+}
@@ -28,6 +28,15 @@ var logLevelNames = [...]string{
 	LogLevelPanic: "panic",
 }
 
+// String returns the name of the log level, such as "info".
+// Unknown levels are formatted as LogLevel(n).
+func (l LogLevel) String() string {
+	if int(l) < len(logLevelNames) {
+		return logLevelNames[l]
+	}
+	return fmt.Sprintf("LogLevel(%d)", uint8(l))
+}
+
 // ErrNoSuchLevel is returned when ParseLogLevel cannot find
 // a log level corresponding to the provided string
 var ErrNoSuchLevel = errors.New("no such log level")
@@ -99,6 +108,24 @@ type Logger interface {
 	Panicf(string, ...any) LogBuilder
 }
 
+// LevelEnabler is implemented by loggers that can report whether
+// they log events with a given level. Wrappers use it to avoid
+// doing any work for events that would be discarded.
+type LevelEnabler interface {
+	// Enabled reports whether events with the given level are logged
+	Enabled(LogLevel) bool
+}
+
+// enabled reports whether l logs events with the given level.
+// Loggers that don't implement LevelEnabler are assumed to
+// log every level.
+func enabled(l Logger, lvl LogLevel) bool {
+	if le, ok := l.(LevelEnabler); ok {
+		return le.Enabled(lvl)
+	}
+	return true
+}
+
 // LogBuilder represents a log event builder
 type LogBuilder interface {
 	// Int adds an int field to the output
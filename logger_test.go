@@ -282,6 +282,22 @@ func BenchmarkPretty(b *testing.B) {
 	})
 }
 
+func TestLogLevelString(t *testing.T) {
+	tests := []struct {
+		lvl  logger.LogLevel
+		want string
+	}{
+		{logger.LogLevelDebug, "debug"},
+		{logger.LogLevelPanic, "panic"},
+		{logger.LogLevel(42), "LogLevel(42)"},
+	}
+	for _, test := range tests {
+		if got := test.lvl.String(); got != test.want {
+			t.Errorf("got: %s, want: %s", got, test.want)
+		}
+	}
+}
+
 func getStr() string {
 	defer buf.Reset()
 	return buf.String()
@@ -0,0 +1,73 @@
+package logtest
+
+import (
+	"fmt"
+	"time"
+
+	"go.elara.ws/logger"
+)
+
+// Int returns an int field, as added by LogBuilder.Int
+func Int(key string, val int) logger.Field {
+	return logger.Field{Key: key, Kind: logger.KindInt, Value: int64(val)}
+}
+
+// Int64 returns an int64 field, as added by LogBuilder.Int64
+func Int64(key string, val int64) logger.Field {
+	return logger.Field{Key: key, Kind: logger.KindInt, Value: val}
+}
+
+// Uint returns a uint field, as added by LogBuilder.Uint
+func Uint(key string, val uint) logger.Field {
+	return logger.Field{Key: key, Kind: logger.KindUint, Value: uint64(val)}
+}
+
+// Uint64 returns a uint64 field, as added by LogBuilder.Uint64
+func Uint64(key string, val uint64) logger.Field {
+	return logger.Field{Key: key, Kind: logger.KindUint, Value: val}
+}
+
+// Float64 returns a float64 field, as added by LogBuilder.Float64
+func Float64(key string, val float64) logger.Field {
+	return logger.Field{Key: key, Kind: logger.KindFloat64, Value: val}
+}
+
+// Float32 returns a float32 field, as added by LogBuilder.Float32
+func Float32(key string, val float32) logger.Field {
+	return logger.Field{Key: key, Kind: logger.KindFloat32, Value: val}
+}
+
+// Bool returns a bool field, as added by LogBuilder.Bool
+func Bool(key string, val bool) logger.Field {
+	return logger.Field{Key: key, Kind: logger.KindBool, Value: val}
+}
+
+// Str returns a string field, as added by LogBuilder.Str
+func Str(key, val string) logger.Field {
+	return logger.Field{Key: key, Kind: logger.KindString, Value: val}
+}
+
+// Stringer returns a string field, as added by LogBuilder.Stringer
+func Stringer(key string, s fmt.Stringer) logger.Field {
+	return logger.Field{Key: key, Kind: logger.KindString, Value: s.String()}
+}
+
+// Bytes returns a []byte field, as added by LogBuilder.Bytes
+func Bytes(key string, b []byte) logger.Field {
+	return logger.Field{Key: key, Kind: logger.KindBytes, Value: b}
+}
+
+// Time returns a time field, as added by LogBuilder.Timestamp
+func Time(key string, t time.Time) logger.Field {
+	return logger.Field{Key: key, Kind: logger.KindTime, Value: t}
+}
+
+// Any returns a field of any type, as added by LogBuilder.Any
+func Any(key string, val any) logger.Field {
+	return logger.Field{Key: key, Kind: logger.KindAny, Value: val}
+}
+
+// Err returns an error field, as added by LogBuilder.Err
+func Err(err error) logger.Field {
+	return logger.Field{Key: "error", Kind: logger.KindError, Value: err}
+}
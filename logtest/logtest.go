@@ -0,0 +1,374 @@
+// Package logtest provides a logger that records events in memory,
+// and helpers to make assertions about them in tests.
+package logtest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.elara.ws/logger"
+)
+
+var _ logger.Logger = (*Observer)(nil)
+
+// Observer implements the logger.Logger interface
+// by recording events in memory. It's safe for
+// concurrent use.
+//
+// Fatal events never exit the process, since that would
+// end the test. Panic events cause a panic unless NoPanic
+// has been called, so that code relying on it can be tested.
+type Observer struct {
+	Level logger.LogLevel
+
+	// Clock returns the current time.
+	// If it's nil, time.Now is used.
+	Clock func() time.Time
+
+	tb      testing.TB
+	mu      sync.Mutex
+	events  []logger.Event
+	noPanic bool
+}
+
+// New creates and returns a new Observer
+// that records events of all levels
+func New() *Observer {
+	return &Observer{Level: logger.LogLevelDebug}
+}
+
+// NewT creates and returns a new Observer that records
+// events and also writes them to the test log using tb.Log,
+// so that they're shown for failed tests or with go test -v.
+//
+// Events must not be sent after the test has completed.
+func NewT(tb testing.TB) *Observer {
+	return &Observer{Level: logger.LogLevelDebug, tb: tb}
+}
+
+// NoPanic prevents the logger from panicking on panic events
+func (o *Observer) NoPanic() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.noPanic = true
+}
+
+// NoExit does nothing, since the observer never exits
+func (o *Observer) NoExit() {}
+
+// SetLevel sets the log level of the logger
+func (o *Observer) SetLevel(l logger.LogLevel) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.Level = l
+}
+
+// Debug creates a new debug event with the given message
+func (o *Observer) Debug(msg string) logger.LogBuilder {
+	return o.newLogBuilder(msg, logger.LogLevelDebug)
+}
+
+// Debugf creates a new debug event with the formatted message
+func (o *Observer) Debugf(format string, v ...any) logger.LogBuilder {
+	return o.newLogBuilder(fmt.Sprintf(format, v...), logger.LogLevelDebug)
+}
+
+// Info creates a new info event with the given message
+func (o *Observer) Info(msg string) logger.LogBuilder {
+	return o.newLogBuilder(msg, logger.LogLevelInfo)
+}
+
+// Infof creates a new info event with the formatted message
+func (o *Observer) Infof(format string, v ...any) logger.LogBuilder {
+	return o.newLogBuilder(fmt.Sprintf(format, v...), logger.LogLevelInfo)
+}
+
+// Warn creates a new warn event with the given message
+func (o *Observer) Warn(msg string) logger.LogBuilder {
+	return o.newLogBuilder(msg, logger.LogLevelWarn)
+}
+
+// Warnf creates a new warn event with the formatted message
+func (o *Observer) Warnf(format string, v ...any) logger.LogBuilder {
+	return o.newLogBuilder(fmt.Sprintf(format, v...), logger.LogLevelWarn)
+}
+
+// Error creates a new error event with the given message
+func (o *Observer) Error(msg string) logger.LogBuilder {
+	return o.newLogBuilder(msg, logger.LogLevelError)
+}
+
+// Errorf creates a new error event with the formatted message
+func (o *Observer) Errorf(format string, v ...any) logger.LogBuilder {
+	return o.newLogBuilder(fmt.Sprintf(format, v...), logger.LogLevelError)
+}
+
+// Fatal creates a new fatal event with the given message
+//
+// Fatal events are recorded, but never exit the process
+func (o *Observer) Fatal(msg string) logger.LogBuilder {
+	return o.newLogBuilder(msg, logger.LogLevelFatal)
+}
+
+// Fatalf creates a new fatal event with the formatted message
+//
+// Fatal events are recorded, but never exit the process
+func (o *Observer) Fatalf(format string, v ...any) logger.LogBuilder {
+	return o.newLogBuilder(fmt.Sprintf(format, v...), logger.LogLevelFatal)
+}
+
+// Panic creates a new panic event with the given message
+//
+// When sent, panic events will cause a panic
+func (o *Observer) Panic(msg string) logger.LogBuilder {
+	return o.newLogBuilder(msg, logger.LogLevelPanic)
+}
+
+// Panicf creates a new panic event with the formatted message
+//
+// When sent, panic events will cause a panic
+func (o *Observer) Panicf(format string, v ...any) logger.LogBuilder {
+	return o.newLogBuilder(fmt.Sprintf(format, v...), logger.LogLevelPanic)
+}
+
+func (o *Observer) newLogBuilder(msg string, lvl logger.LogLevel) logger.LogBuilder {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if lvl < o.Level {
+		return logger.NopLogBuilder{}
+	}
+	return logger.NewEventLogBuilderClock(lvl, msg, o.Now, o.record)
+}
+
+// Enabled reports whether events with the given level are recorded
+func (o *Observer) Enabled(lvl logger.LogLevel) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return lvl >= o.Level
+}
+
+// LogEvent records a collected event, keeping its time.
+// It implements the logger.EventLogger interface.
+func (o *Observer) LogEvent(e *logger.Event) {
+	if o.Enabled(e.Level) {
+		o.record(e)
+	}
+}
+
+// Now returns the current time using the clock
+func (o *Observer) Now() time.Time {
+	if o.Clock != nil {
+		return o.Clock()
+	}
+	return time.Now()
+}
+
+// record stores the event, writes it to the test
+// log if required, and panics on panic events
+func (o *Observer) record(e *logger.Event) {
+	o.mu.Lock()
+	o.events = append(o.events, *e)
+	noPanic := o.noPanic
+	o.mu.Unlock()
+
+	if o.tb != nil {
+		o.tb.Log(Format(*e))
+	}
+
+	if e.Level == logger.LogLevelPanic && !noPanic {
+		panic(logger.NewPanicEvent(e))
+	}
+}
+
+// Events returns all the recorded events, in order
+func (o *Observer) Events() []logger.Event {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	out := make([]logger.Event, len(o.events))
+	copy(out, o.events)
+	return out
+}
+
+// Len returns the amount of recorded events
+func (o *Observer) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.events)
+}
+
+// Reset removes all the recorded events
+func (o *Observer) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = nil
+}
+
+// Filter returns the recorded events for which fn returns true
+func (o *Observer) Filter(fn func(logger.Event) bool) []logger.Event {
+	var out []logger.Event
+	for _, e := range o.Events() {
+		if fn(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// FilterLevel returns the recorded events with the given level
+func (o *Observer) FilterLevel(lvl logger.LogLevel) []logger.Event {
+	return o.Filter(func(e logger.Event) bool {
+		return e.Level == lvl
+	})
+}
+
+// FilterMessage returns the recorded events with the given message
+func (o *Observer) FilterMessage(msg string) []logger.Event {
+	return o.Filter(func(e logger.Event) bool {
+		return e.Message == msg
+	})
+}
+
+// FilterField returns the recorded events that contain the given field
+func (o *Observer) FilterField(f logger.Field) []logger.Event {
+	return o.Filter(func(e logger.Event) bool {
+		return HasField(e, f)
+	})
+}
+
+// Count returns the amount of recorded events
+// with the given level and message
+func (o *Observer) Count(lvl logger.LogLevel, msg string) int {
+	return len(o.Filter(func(e logger.Event) bool {
+		return e.Level == lvl && e.Message == msg
+	}))
+}
+
+// Logged reports whether an event with the given level and message,
+// containing all the given fields, has been recorded
+func (o *Observer) Logged(lvl logger.LogLevel, msg string, fields ...logger.Field) bool {
+	return len(o.Filter(func(e logger.Event) bool {
+		return Match(e, lvl, msg, fields...)
+	})) > 0
+}
+
+// AssertLogged reports a test error if no event with the given level
+// and message, containing all the given fields, has been recorded
+func (o *Observer) AssertLogged(tb testing.TB, lvl logger.LogLevel, msg string, fields ...logger.Field) bool {
+	tb.Helper()
+	if o.Logged(lvl, msg, fields...) {
+		return true
+	}
+	tb.Errorf("expected event was not logged: %s\n%s", formatExpected(lvl, msg, fields), o.dump())
+	return false
+}
+
+// RequireLogged is like AssertLogged, but stops
+// the test if the event hasn't been recorded
+func (o *Observer) RequireLogged(tb testing.TB, lvl logger.LogLevel, msg string, fields ...logger.Field) {
+	tb.Helper()
+	if !o.Logged(lvl, msg, fields...) {
+		tb.Fatalf("expected event was not logged: %s\n%s", formatExpected(lvl, msg, fields), o.dump())
+	}
+}
+
+// AssertNotLogged reports a test error if an event with the given
+// level and message, containing all the given fields, has been recorded
+func (o *Observer) AssertNotLogged(tb testing.TB, lvl logger.LogLevel, msg string, fields ...logger.Field) bool {
+	tb.Helper()
+	if !o.Logged(lvl, msg, fields...) {
+		return true
+	}
+	tb.Errorf("unexpected event was logged: %s\n%s", formatExpected(lvl, msg, fields), o.dump())
+	return false
+}
+
+// dump returns all the recorded events formatted for a test failure
+func (o *Observer) dump() string {
+	events := o.Events()
+	if len(events) == 0 {
+		return "no events were logged"
+	}
+	var sb strings.Builder
+	sb.WriteString("logged events:")
+	for _, e := range events {
+		sb.WriteString("\n\t")
+		sb.WriteString(Format(e))
+	}
+	return sb.String()
+}
+
+// Match reports whether e has the given level and
+// message, and contains all the given fields
+func Match(e logger.Event, lvl logger.LogLevel, msg string, fields ...logger.Field) bool {
+	if e.Level != lvl || e.Message != msg {
+		return false
+	}
+	for _, f := range fields {
+		if !HasField(e, f) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasField reports whether e contains a field with the
+// same key, kind and value as f. Errors are compared
+// using errors.Is, or by their message if that fails.
+func HasField(e logger.Event, f logger.Field) bool {
+	for _, ef := range e.Fields {
+		if ef.Key == f.Key && ef.Kind == f.Kind && fieldValueEqual(ef, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldValueEqual reports whether the values
+// of two fields of the same kind are equal
+func fieldValueEqual(a, b logger.Field) bool {
+	switch a.Kind {
+	case logger.KindTime:
+		at, _ := a.Value.(time.Time)
+		bt, _ := b.Value.(time.Time)
+		return at.Equal(bt)
+	case logger.KindError:
+		aerr, _ := a.Value.(error)
+		berr, _ := b.Value.(error)
+		if aerr == nil || berr == nil {
+			return aerr == berr
+		}
+		return errors.Is(aerr, berr) || aerr.Error() == berr.Error()
+	default:
+		return reflect.DeepEqual(a.Value, b.Value)
+	}
+}
+
+// Format returns a human-readable representation of e,
+// such as: info "Request handled" status=200
+func Format(e logger.Event) string {
+	return formatExpected(e.Level, e.Message, e.Fields)
+}
+
+// formatExpected formats a level, message and fields
+func formatExpected(lvl logger.LogLevel, msg string, fields []logger.Field) string {
+	var sb strings.Builder
+	sb.WriteString(lvl.String())
+	sb.WriteByte(' ')
+	fmt.Fprintf(&sb, "%q", msg)
+	for _, f := range fields {
+		sb.WriteByte(' ')
+		sb.WriteString(f.Key)
+		sb.WriteByte('=')
+		if err, ok := f.Value.(error); f.Kind == logger.KindError && (!ok || err == nil) {
+			sb.WriteString("<nil>")
+		} else {
+			sb.WriteString(f.ValueString())
+		}
+	}
+	return sb.String()
+}
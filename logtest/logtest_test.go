@@ -0,0 +1,144 @@
+package logtest_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.elara.ws/logger"
+	"go.elara.ws/logger/logtest"
+)
+
+// fakeTB records failures instead of failing the test
+type fakeTB struct {
+	testing.TB
+	failures []string
+	fatal    bool
+}
+
+func (ft *fakeTB) Helper() {}
+
+func (ft *fakeTB) Errorf(format string, v ...any) {
+	ft.failures = append(ft.failures, fmt.Sprintf(format, v...))
+}
+
+func (ft *fakeTB) Fatalf(format string, v ...any) {
+	ft.Errorf(format, v...)
+	ft.fatal = true
+}
+
+func TestObserver(t *testing.T) {
+	t.Run("record", func(t *testing.T) {
+		obs := logtest.New()
+		err := errors.New("err")
+
+		obs.Info("Request").Int("status", 200).Str("path", "/").Send()
+		obs.Error("Failed").Err(err).Send()
+		obs.Debugf("n=%d", 1).Send()
+
+		events := obs.Events()
+		if got, want := len(events), 3; got != want {
+			t.Fatalf("got: %d events, want: %d", got, want)
+		}
+		if got, want := events[0].Fields[1].Key, "path"; got != want {
+			t.Errorf("got: %s, want: %s", got, want)
+		}
+
+		obs.RequireLogged(t, logger.LogLevelInfo, "Request", logtest.Int("status", 200))
+		obs.RequireLogged(t, logger.LogLevelError, "Failed", logtest.Err(err))
+		obs.RequireLogged(t, logger.LogLevelDebug, "n=1")
+		obs.AssertNotLogged(t, logger.LogLevelInfo, "Request", logtest.Int("status", 500))
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		obs := logtest.New()
+		for i := 0; i < 3; i++ {
+			obs.Info("Test").Int("i", i).Send()
+		}
+		obs.Warn("Test").Send()
+
+		if got, want := obs.Count(logger.LogLevelInfo, "Test"), 3; got != want {
+			t.Errorf("got: %d, want: %d", got, want)
+		}
+		if got, want := len(obs.FilterLevel(logger.LogLevelWarn)), 1; got != want {
+			t.Errorf("got: %d, want: %d", got, want)
+		}
+		if got, want := len(obs.FilterMessage("Test")), 4; got != want {
+			t.Errorf("got: %d, want: %d", got, want)
+		}
+		if got, want := len(obs.FilterField(logtest.Int("i", 1))), 1; got != want {
+			t.Errorf("got: %d, want: %d", got, want)
+		}
+
+		obs.Reset()
+		if got, want := obs.Len(), 0; got != want {
+			t.Errorf("got: %d, want: %d", got, want)
+		}
+	})
+
+	t.Run("level", func(t *testing.T) {
+		obs := logtest.New()
+		obs.SetLevel(logger.LogLevelWarn)
+		obs.Info("Test").Send()
+		if got, want := obs.Len(), 0; got != want {
+			t.Errorf("got: %d, want: %d", got, want)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		obs := logtest.New()
+		obs.Info("Test").Str("a", "b").Send()
+
+		ft := &fakeTB{}
+		obs.RequireLogged(ft, logger.LogLevelInfo, "Test", logtest.Str("a", "c"))
+		if !ft.fatal || len(ft.failures) != 1 {
+			t.Fatalf("expected fatal failure, got: %v", ft.failures)
+		}
+		if got := ft.failures[0]; !strings.Contains(got, `info "Test" a=c`) || !strings.Contains(got, `info "Test" a=b`) {
+			t.Errorf("unexpected failure message: %s", got)
+		}
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		obs := logtest.New()
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic")
+				}
+			}()
+			obs.Panic("Test").Send()
+		}()
+
+		obs.NoPanic()
+		obs.Panic("Test").Send()
+		obs.Fatal("Test").Send()
+		if got, want := obs.Len(), 3; got != want {
+			t.Errorf("got: %d, want: %d", got, want)
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		obs := logtest.New()
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				obs.Info("Test").Send()
+			}()
+		}
+		wg.Wait()
+		if got, want := obs.Count(logger.LogLevelInfo, "Test"), 10; got != want {
+			t.Errorf("got: %d, want: %d", got, want)
+		}
+	})
+
+	t.Run("tb", func(t *testing.T) {
+		obs := logtest.NewT(t)
+		obs.Info("Routed to t.Log").Int("n", 1).Send()
+		obs.RequireLogged(t, logger.LogLevelInfo, "Routed to t.Log")
+	})
+}
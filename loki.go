@@ -0,0 +1,364 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ Logger = (*LokiLogger)(nil)
+
+// LokiLineFormat controls how the fields that
+// aren't labels are encoded in a Loki log line
+type LokiLineFormat uint8
+
+// Loki line formats
+const (
+	// LokiJSON encodes lines as JSON objects with
+	// the same keys as JSONLogger
+	LokiJSON LokiLineFormat = iota
+	// LokiLogfmt encodes lines using logfmt
+	LokiLogfmt
+)
+
+// LokiLogger implements the Logger interface by pushing
+// events to Grafana Loki using a LokiClient.
+//
+// Events are grouped into streams by the fields listed in
+// Labels. The remaining fields are encoded in the log line.
+type LokiLogger struct {
+	Client *LokiClient
+	Level  LogLevel
+
+	// Labels are the keys of the fields used as stream labels.
+	// The "level" label is set to the level of the event.
+	Labels []string
+	// StaticLabels are added to every stream,
+	// such as {"service": "api"}
+	StaticLabels map[string]string
+	// LineFormat controls how log lines are encoded
+	LineFormat LokiLineFormat
+
+	Environment
+
+	noPanic bool
+	noExit  bool
+}
+
+// NewLoki creates and returns a new LokiLogger
+// that uses the level as its only label
+func NewLoki(client *LokiClient) *LokiLogger {
+	return &LokiLogger{
+		Client: client,
+		Level:  LogLevelInfo,
+		Labels: []string{"level"},
+	}
+}
+
+// NoPanic prevents the logger from panicking on panic events
+func (ll *LokiLogger) NoPanic() {
+	ll.noPanic = true
+}
+
+// NoExit prevents the logger from exiting on fatal events
+func (ll *LokiLogger) NoExit() {
+	ll.noExit = true
+}
+
+// SetLevel sets the log level of the logger
+func (ll *LokiLogger) SetLevel(l LogLevel) {
+	ll.Level = l
+}
+
+// Enabled reports whether events with the given level are logged
+func (ll *LokiLogger) Enabled(lvl LogLevel) bool {
+	return ll.Client != nil && lvl >= ll.Level
+}
+
+// Debug creates a new debug event with the given message
+func (ll *LokiLogger) Debug(msg string) LogBuilder {
+	return newLokiLogBuilder(ll, msg, LogLevelDebug)
+}
+
+// Debugf creates a new debug event with the formatted message
+func (ll *LokiLogger) Debugf(format string, v ...any) LogBuilder {
+	return newLokiLogBuilder(ll, fmt.Sprintf(format, v...), LogLevelDebug)
+}
+
+// Info creates a new info event with the given message
+func (ll *LokiLogger) Info(msg string) LogBuilder {
+	return newLokiLogBuilder(ll, msg, LogLevelInfo)
+}
+
+// Infof creates a new info event with the formatted message
+func (ll *LokiLogger) Infof(format string, v ...any) LogBuilder {
+	return newLokiLogBuilder(ll, fmt.Sprintf(format, v...), LogLevelInfo)
+}
+
+// Warn creates a new warn event with the given message
+func (ll *LokiLogger) Warn(msg string) LogBuilder {
+	return newLokiLogBuilder(ll, msg, LogLevelWarn)
+}
+
+// Warnf creates a new warn event with the formatted message
+func (ll *LokiLogger) Warnf(format string, v ...any) LogBuilder {
+	return newLokiLogBuilder(ll, fmt.Sprintf(format, v...), LogLevelWarn)
+}
+
+// Error creates a new error event with the given message
+func (ll *LokiLogger) Error(msg string) LogBuilder {
+	return newLokiLogBuilder(ll, msg, LogLevelError)
+}
+
+// Errorf creates a new error event with the formatted message
+func (ll *LokiLogger) Errorf(format string, v ...any) LogBuilder {
+	return newLokiLogBuilder(ll, fmt.Sprintf(format, v...), LogLevelError)
+}
+
+// Fatal creates a new fatal event with the given message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (ll *LokiLogger) Fatal(msg string) LogBuilder {
+	return newLokiLogBuilder(ll, msg, LogLevelFatal)
+}
+
+// Fatalf creates a new fatal event with the formatted message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (ll *LokiLogger) Fatalf(format string, v ...any) LogBuilder {
+	return newLokiLogBuilder(ll, fmt.Sprintf(format, v...), LogLevelFatal)
+}
+
+// Panic creates a new panic event with the given message
+//
+// When sent, panic events will cause a panic
+func (ll *LokiLogger) Panic(msg string) LogBuilder {
+	return newLokiLogBuilder(ll, msg, LogLevelPanic)
+}
+
+// Panicf creates a new panic event with the formatted message
+//
+// When sent, panic events will cause a panic
+func (ll *LokiLogger) Panicf(format string, v ...any) LogBuilder {
+	return newLokiLogBuilder(ll, fmt.Sprintf(format, v...), LogLevelPanic)
+}
+
+func newLokiLogBuilder(ll *LokiLogger, msg string, lvl LogLevel) LogBuilder {
+	if !ll.Enabled(lvl) {
+		return NopLogBuilder{}
+	}
+	return newEventLogBuilder(lvl, msg, ll.Now, ll.send)
+}
+
+// LogEvent logs a collected event, keeping its time
+func (ll *LokiLogger) LogEvent(e *Event) {
+	if ll.Enabled(e.Level) {
+		ll.send(e)
+	}
+}
+
+// send converts the event to a Loki entry and pushes it.
+// Fatal and panic events are flushed immediately so that
+// they aren't lost when the program terminates. Errors
+// are passed to the client's error handler.
+func (ll *LokiLogger) send(e *Event) {
+	ll.Client.handleError(ll.Client.Push(ll.Entry(e)))
+
+	if e.Level == LogLevelFatal && !ll.noExit {
+		ll.Client.handleError(ll.Client.Flush())
+		ll.exit()
+	} else if e.Level == LogLevelPanic && !ll.noPanic {
+		ll.Client.handleError(ll.Client.Flush())
+		ll.doPanic(NewPanicEvent(e))
+	}
+}
+
+// Entry converts an event to a Loki entry
+func (ll *LokiLogger) Entry(e *Event) LokiEntry {
+	labels := make(map[string]string, len(ll.StaticLabels)+len(ll.Labels))
+	for k, v := range ll.StaticLabels {
+		labels[lokiLabelName(k)] = v
+	}
+
+	line := &Event{Level: e.Level, Message: e.Message, Time: e.Time}
+	for _, f := range e.Fields {
+		if f.Kind != KindError && ll.isLabel(f.Key) {
+			labels[lokiLabelName(f.Key)] = f.ValueString()
+		} else {
+			line.Fields = append(line.Fields, f)
+		}
+	}
+	if ll.isLabel("level") {
+		labels["level"] = e.Level.String()
+	}
+
+	buf := &bytes.Buffer{}
+	switch ll.LineFormat {
+	case LokiLogfmt:
+		writeLogfmt(buf, line)
+	default:
+		writeLokiJSON(buf, line)
+	}
+
+	return LokiEntry{
+		Labels: labels,
+		Time:   e.Time,
+		Line:   buf.String(),
+	}
+}
+
+// isLabel reports whether the given key is used as a label
+func (ll *LokiLogger) isLabel(key string) bool {
+	for _, label := range ll.Labels {
+		if label == key {
+			return true
+		}
+	}
+	return false
+}
+
+// lokiLabelName converts a key to a valid Loki label name,
+// replacing invalid characters with underscores
+func lokiLabelName(key string) string {
+	name := []byte(key)
+	for i, c := range name {
+		valid := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9')
+		if !valid {
+			name[i] = '_'
+		}
+	}
+	return string(name)
+}
+
+// writeLokiJSON writes the event's message, level and
+// fields to the buffer as a JSON object, in the same
+// order as JSONLogger
+func writeLokiJSON(buf *bytes.Buffer, e *Event) {
+	buf.WriteString(`{"msg":`)
+	writeJSONString(buf, e.Message)
+	buf.WriteString(`,"level":`)
+	writeJSONString(buf, e.Level.String())
+	for _, f := range e.Fields {
+		buf.WriteByte(',')
+		writeJSONString(buf, f.Key)
+		buf.WriteByte(':')
+		writeJSONValue(buf, f)
+	}
+	buf.WriteByte('}')
+}
+
+// writeLogfmt writes the event's level, message
+// and fields to the buffer using logfmt
+func writeLogfmt(buf *bytes.Buffer, e *Event) {
+	buf.WriteString("level=")
+	buf.WriteString(e.Level.String())
+	buf.WriteString(" msg=")
+	writeLogfmtValue(buf, e.Message)
+	for _, f := range e.Fields {
+		buf.WriteByte(' ')
+		buf.WriteString(f.Key)
+		buf.WriteByte('=')
+		writeLogfmtValue(buf, f.ValueString())
+	}
+}
+
+// writeLogfmtValue writes a logfmt value to the buffer,
+// quoting it if required
+func writeLogfmtValue(buf *bytes.Buffer, val string) {
+	if val != "" && !strings.ContainsAny(val, " =\"\t\r\n") {
+		buf.WriteString(val)
+		return
+	}
+	buf.WriteString(strconv.Quote(val))
+}
+
+// LokiEntry is a single log line pushed to Loki
+type LokiEntry struct {
+	Labels map[string]string
+	Time   time.Time
+	Line   string
+}
+
+// LokiPush encodes batches of entries written by a LokiClient
+// as the body of a request to Loki's JSON push API, grouping
+// entries with the same labels into a single stream
+var LokiPush = HTTPBodyFormat{
+	ContentType: "application/json",
+	Encode: func(buf *bytes.Buffer, events [][]byte) error {
+		var keys []string
+		streams := map[string][][]byte{}
+		for _, event := range events {
+			labels, value, ok := bytes.Cut(event, []byte{'\n'})
+			if !ok {
+				return errors.New("loki: event without labels")
+			}
+			key := string(labels)
+			if _, ok := streams[key]; !ok {
+				keys = append(keys, key)
+			}
+			streams[key] = append(streams[key], value)
+		}
+
+		buf.WriteString(`{"streams":[`)
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(`{"stream":`)
+			buf.WriteString(key)
+			buf.WriteString(`,"values":`)
+			HTTPJSONArray.Encode(buf, streams[key])
+			buf.WriteByte('}')
+		}
+		buf.WriteString("]}")
+		return nil
+	},
+}
+
+// LokiClient batches entries and pushes them to Loki using the JSON
+// push API. It's built on an HTTPWriter that uses the LokiPush body
+// format, and shares its endpoint and batching settings, but it isn't
+// an io.Writer, since entries have to be added using Push. For
+// multi-tenant Loki, the X-Scope-OrgID header has to be set
+// in Headers.
+type LokiClient struct {
+	*HTTPEndpoint
+	*Batching
+
+	hw *HTTPWriter
+}
+
+// NewLokiClient creates a new LokiClient that pushes entries
+// to the given endpoint and starts a goroutine that pushes
+// them in the background every flush interval.
+func NewLokiClient(endpoint string, interval time.Duration) *LokiClient {
+	hw := NewHTTPWriter(endpoint, interval)
+	hw.Format = LokiPush
+	hw.Compress = false
+	hw.BatchSize = 1000
+	return &LokiClient{&hw.HTTPEndpoint, &hw.Batching, hw}
+}
+
+// Push adds an entry to the pending entries
+func (lc *LokiClient) Push(entry LokiEntry) error {
+	labels, err := json.Marshal(entry.Labels)
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal([2]string{strconv.FormatInt(entry.Time.UnixNano(), 10), entry.Line})
+	if err != nil {
+		return err
+	}
+
+	// Labels and values are separated by a newline, which can't
+	// occur in encoded JSON, so that LokiPush can group them
+	event := make([]byte, 0, len(labels)+len(value)+1)
+	event = append(event, labels...)
+	event = append(event, '\n')
+	event = append(event, value...)
+	return lc.enqueue(event)
+}
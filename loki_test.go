@@ -0,0 +1,167 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"go.elara.ws/logger"
+)
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// lokiStreams decodes the streams pushed to hc
+func lokiStreams(t *testing.T, hc *httpCollector) []lokiStream {
+	t.Helper()
+	var streams []lokiStream
+	for _, body := range hc.requestBodies() {
+		var req struct {
+			Streams []lokiStream `json:"streams"`
+		}
+		if err := json.Unmarshal([]byte(body), &req); err != nil {
+			t.Fatal(err)
+		}
+		streams = append(streams, req.Streams...)
+	}
+	return streams
+}
+
+// newLokiTest creates a LokiClient that pushes to hc
+func newLokiTest(t *testing.T, hc *httpCollector) *logger.LokiClient {
+	t.Helper()
+	lc := logger.NewLokiClient(serveCollector(t, hc)+"/loki/api/v1/push", time.Hour)
+	startBatchingTest(t, lc, lc.Batching)
+	return lc
+}
+
+func TestLoki(t *testing.T) {
+	t.Run("streams", func(t *testing.T) {
+		hc := &httpCollector{}
+		lc := newLokiTest(t, hc)
+		lc.Headers = map[string]string{"X-Scope-OrgID": "tenant"}
+		lokilog := logger.NewLoki(lc)
+		lokilog.Labels = []string{"level", "service"}
+		lokilog.StaticLabels = map[string]string{"job": "test"}
+
+		lokilog.Info("one").Str("service", "api").Int("n", 1).Send()
+		lokilog.Info("two").Str("service", "db").Send()
+		lokilog.Warn("three").Str("service", "api").Send()
+		lokilog.Info("four").Str("service", "api").Err(errors.New("err")).Send()
+
+		if err := lc.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := hc.headers[0].Get("X-Scope-OrgID"), "tenant"; got != want {
+			t.Errorf("got: %s tenant, want: %s", got, want)
+		}
+		streams := lokiStreams(t, hc)
+		if got, want := len(streams), 3; got != want {
+			t.Fatalf("got: %d streams, want: %d", got, want)
+		}
+
+		first := streams[0]
+		for k, want := range map[string]string{"job": "test", "level": "info", "service": "api"} {
+			if got := first.Stream[k]; got != want {
+				t.Errorf("got: %s label %s, want: %s", got, k, want)
+			}
+		}
+		if got, want := len(first.Values), 2; got != want {
+			t.Fatalf("got: %d values, want: %d", got, want)
+		}
+		if got, want := first.Values[0][1], `{"msg":"one","level":"info","n":1}`; got != want {
+			t.Errorf("got: %s, want: %s", got, want)
+		}
+		if got, want := first.Values[1][1], `{"msg":"four","level":"info","error":"err"}`; got != want {
+			t.Errorf("got: %s, want: %s", got, want)
+		}
+	})
+
+	t.Run("logfmt", func(t *testing.T) {
+		hc := &httpCollector{}
+		lc := newLokiTest(t, hc)
+		lokilog := logger.NewLoki(lc)
+		lokilog.LineFormat = logger.LokiLogfmt
+
+		lokilog.Info("Test message").Str("a", "b c").Int("n", 1).Str("empty", "").Send()
+		if err := lc.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := lokiStreams(t, hc)[0].Values[0][1], `level=info msg="Test message" a="b c" n=1 empty=""`; got != want {
+			t.Errorf("got: %s, want: %s", got, want)
+		}
+	})
+
+	t.Run("json-escape", func(t *testing.T) {
+		hc := &httpCollector{}
+		lc := newLokiTest(t, hc)
+		lokilog := logger.NewLoki(lc)
+
+		lokilog.Info("x \"y\"\nz").Str("a", "b\\\"c\"\n").Err(errors.New("e\"rr")).Send()
+		if err := lc.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		line := lokiStreams(t, hc)[0].Values[0][1]
+		var got map[string]string
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("invalid JSON line %s: %v", line, err)
+		}
+		want := map[string]string{"msg": "x \"y\"\nz", "level": "info", "a": "b\\\"c\"\n", "error": "e\"rr"}
+		for k, v := range want {
+			if got[k] != v {
+				t.Errorf("%s: got: %q, want: %q", k, got[k], v)
+			}
+		}
+	})
+
+	t.Run("not-writer", func(t *testing.T) {
+		lc := newLokiTest(t, &httpCollector{})
+		if _, ok := any(lc).(io.Writer); ok {
+			t.Error("LokiClient shouldn't implement io.Writer")
+		}
+
+		buf := &bytes.Buffer{}
+		if err := logger.LokiPush.Encode(buf, [][]byte{[]byte(`{"msg":"Test"}`)}); err == nil {
+			t.Error("expected error for event without labels")
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		lc := newLokiTest(t, &httpCollector{})
+		lokilog := logger.NewLoki(lc)
+		assertClosedError(t, lc, lc.Batching, func() { lokilog.Info("Test").Send() })
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		hc := &httpCollector{failures: 2}
+		lc := newLokiTest(t, hc)
+		lokilog := logger.NewLoki(lc)
+
+		lokilog.Info("Test").Send()
+		if err := lc.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(lokiStreams(t, hc)), 1; got != want {
+			t.Errorf("got: %d streams, want: %d", got, want)
+		}
+	})
+
+	t.Run("give-up", func(t *testing.T) {
+		lc := newLokiTest(t, &httpCollector{failures: 10})
+		lc.MaxRetries = 2
+		lokilog := logger.NewLoki(lc)
+
+		lokilog.Info("Test").Send()
+		if err := lc.Flush(); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
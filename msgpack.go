@@ -0,0 +1,316 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// This file contains a minimal msgpack encoder, supporting only
+// the types needed to encode events, and a decoder for maps of
+// strings, which is all that's needed to read Fluentd acks.
+
+// msgpackWriteNil writes a nil value to the buffer
+func msgpackWriteNil(buf *bytes.Buffer) {
+	buf.WriteByte(0xc0)
+}
+
+// msgpackWriteBool writes a bool to the buffer
+func msgpackWriteBool(buf *bytes.Buffer, b bool) {
+	if b {
+		buf.WriteByte(0xc3)
+	} else {
+		buf.WriteByte(0xc2)
+	}
+}
+
+// msgpackWriteInt writes a signed integer to the
+// buffer using the smallest possible encoding
+func msgpackWriteInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0:
+		msgpackWriteUint(buf, uint64(i))
+	case i >= -32:
+		buf.WriteByte(byte(i))
+	case i >= math.MinInt8:
+		buf.Write([]byte{0xd0, byte(i)})
+	case i >= math.MinInt16:
+		buf.WriteByte(0xd1)
+		binary.Write(buf, binary.BigEndian, int16(i))
+	case i >= math.MinInt32:
+		buf.WriteByte(0xd2)
+		binary.Write(buf, binary.BigEndian, int32(i))
+	default:
+		buf.WriteByte(0xd3)
+		binary.Write(buf, binary.BigEndian, i)
+	}
+}
+
+// msgpackWriteUint writes an unsigned integer to the
+// buffer using the smallest possible encoding
+func msgpackWriteUint(buf *bytes.Buffer, u uint64) {
+	switch {
+	case u <= 0x7f:
+		buf.WriteByte(byte(u))
+	case u <= math.MaxUint8:
+		buf.Write([]byte{0xcc, byte(u)})
+	case u <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		binary.Write(buf, binary.BigEndian, uint16(u))
+	case u <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		binary.Write(buf, binary.BigEndian, uint32(u))
+	default:
+		buf.WriteByte(0xcf)
+		binary.Write(buf, binary.BigEndian, u)
+	}
+}
+
+// msgpackWriteFloat32 writes a float32 to the buffer
+func msgpackWriteFloat32(buf *bytes.Buffer, f float32) {
+	buf.WriteByte(0xca)
+	binary.Write(buf, binary.BigEndian, math.Float32bits(f))
+}
+
+// msgpackWriteFloat64 writes a float64 to the buffer
+func msgpackWriteFloat64(buf *bytes.Buffer, f float64) {
+	buf.WriteByte(0xcb)
+	binary.Write(buf, binary.BigEndian, math.Float64bits(f))
+}
+
+// msgpackWriteString writes a string to the buffer
+func msgpackWriteString(buf *bytes.Buffer, s string) {
+	n := len(s)
+	switch {
+	case n <= 31:
+		buf.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		buf.Write([]byte{0xd9, byte(n)})
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xda)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdb)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+	buf.WriteString(s)
+}
+
+// msgpackWriteBin writes a byte slice to the buffer
+func msgpackWriteBin(buf *bytes.Buffer, b []byte) {
+	n := len(b)
+	switch {
+	case n <= math.MaxUint8:
+		buf.Write([]byte{0xc4, byte(n)})
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xc5)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xc6)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+	buf.Write(b)
+}
+
+// msgpackWriteArrayHeader writes the header of
+// an array with n elements to the buffer
+func msgpackWriteArrayHeader(buf *bytes.Buffer, n int) {
+	switch {
+	case n <= 15:
+		buf.WriteByte(0x90 | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xdc)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdd)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+// msgpackWriteMapHeader writes the header of
+// a map with n entries to the buffer
+func msgpackWriteMapHeader(buf *bytes.Buffer, n int) {
+	switch {
+	case n <= 15:
+		buf.WriteByte(0x80 | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xde)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdf)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+// msgpackWriteEventTime writes t to the buffer using
+// Fluentd's EventTime extension type, which has
+// nanosecond precision
+func msgpackWriteEventTime(buf *bytes.Buffer, t time.Time) {
+	buf.Write([]byte{0xd7, 0x00})
+	binary.Write(buf, binary.BigEndian, uint32(t.Unix()))
+	binary.Write(buf, binary.BigEndian, uint32(t.Nanosecond()))
+}
+
+// msgpackWriteField writes the value of a field to the buffer
+func msgpackWriteField(buf *bytes.Buffer, f Field) {
+	switch f.Kind {
+	case KindInt:
+		msgpackWriteInt(buf, f.Value.(int64))
+	case KindUint:
+		msgpackWriteUint(buf, f.Value.(uint64))
+	case KindFloat32:
+		msgpackWriteFloat32(buf, f.Value.(float32))
+	case KindFloat64:
+		msgpackWriteFloat64(buf, f.Value.(float64))
+	case KindBool:
+		msgpackWriteBool(buf, f.Value.(bool))
+	case KindString:
+		msgpackWriteString(buf, f.Value.(string))
+	case KindBytes:
+		msgpackWriteBin(buf, f.Value.([]byte))
+	case KindTime:
+		msgpackWriteString(buf, f.Value.(time.Time).Format(time.RFC3339Nano))
+	case KindError:
+		msgpackWriteString(buf, f.Value.(error).Error())
+	default:
+		// Values of arbitrary types are converted to their JSON
+		// representation so that they're encoded the same way
+		// as they would be by JSONLogger
+		data, err := json.Marshal(f.Value)
+		if err != nil {
+			msgpackWriteString(buf, fmt.Sprint(f.Value))
+			return
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var val any
+		if err := dec.Decode(&val); err != nil {
+			msgpackWriteString(buf, string(data))
+			return
+		}
+		msgpackWriteJSON(buf, val)
+	}
+}
+
+// msgpackWriteJSON writes a value decoded
+// by encoding/json to the buffer
+func msgpackWriteJSON(buf *bytes.Buffer, val any) {
+	switch val := val.(type) {
+	case nil:
+		msgpackWriteNil(buf)
+	case bool:
+		msgpackWriteBool(buf, val)
+	case string:
+		msgpackWriteString(buf, val)
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			msgpackWriteInt(buf, i)
+		} else if f, err := val.Float64(); err == nil {
+			msgpackWriteFloat64(buf, f)
+		} else {
+			msgpackWriteString(buf, val.String())
+		}
+	case []any:
+		msgpackWriteArrayHeader(buf, len(val))
+		for _, elem := range val {
+			msgpackWriteJSON(buf, elem)
+		}
+	case map[string]any:
+		msgpackWriteMapHeader(buf, len(val))
+		for k, v := range val {
+			msgpackWriteString(buf, k)
+			msgpackWriteJSON(buf, v)
+		}
+	default:
+		msgpackWriteString(buf, fmt.Sprint(val))
+	}
+}
+
+// errMsgpackType is returned when a msgpack
+// value has an unexpected type
+var errMsgpackType = errors.New("msgpack: unexpected type")
+
+// msgpackReadStringMap reads a map whose keys
+// and values are strings from r
+func msgpackReadStringMap(r *bufio.Reader) (map[string]string, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	var n int
+	switch {
+	case b&0xf0 == 0x80:
+		n = int(b & 0x0f)
+	case b == 0xde:
+		var n16 uint16
+		err = binary.Read(r, binary.BigEndian, &n16)
+		n = int(n16)
+	case b == 0xdf:
+		var n32 uint32
+		err = binary.Read(r, binary.BigEndian, &n32)
+		n = int(n32)
+	default:
+		return nil, errMsgpackType
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		k, err := msgpackReadString(r)
+		if err != nil {
+			return nil, err
+		}
+		v, err := msgpackReadString(r)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+// msgpackReadString reads a string from r
+func msgpackReadString(r *bufio.Reader) (string, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+
+	var n int
+	switch {
+	case b&0xe0 == 0xa0:
+		n = int(b & 0x1f)
+	case b == 0xd9:
+		var n8 uint8
+		err = binary.Read(r, binary.BigEndian, &n8)
+		n = int(n8)
+	case b == 0xda:
+		var n16 uint16
+		err = binary.Read(r, binary.BigEndian, &n16)
+		n = int(n16)
+	case b == 0xdb:
+		var n32 uint32
+		err = binary.Read(r, binary.BigEndian, &n32)
+		n = int(n32)
+	default:
+		return "", errMsgpackType
+	}
+	if err != nil {
+		return "", err
+	}
+
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -1,27 +1,111 @@
 package logger
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"sync"
+	"time"
 )
 
 var _ Logger = (*MultiLogger)(nil)
 
-// MultiLogger implements the Logger interface by
-// writing to multiple underlying loggers sequentially.
+// ErrChildTimeout is reported when an underlying logger of a
+// MultiLogger doesn't finish sending an event before the timeout
+var ErrChildTimeout = errors.New("logger timed out")
+
+// ChildError is reported when an underlying logger
+// of a MultiLogger panics or times out
+type ChildError struct {
+	Logger Logger
+	Err    error
+}
+
+// Error returns the type of the logger and the error
+func (ce *ChildError) Error() string {
+	return fmt.Sprintf("%T: %v", ce.Logger, ce.Err)
+}
+
+// Unwrap returns the underlying error
+func (ce *ChildError) Unwrap() error {
+	return ce.Err
+}
+
+// ExitPolicy controls which loggers exit or panic on
+// fatal and panic events sent to a MultiLogger
+type ExitPolicy uint8
+
+// Exit policies
+const (
+	// ExitPolicyMulti prevents the underlying loggers from exiting
+	// or panicking, so that the MultiLogger exits or panics once,
+	// after all of them have written the event.
+	ExitPolicyMulti ExitPolicy = iota
+	// ExitPolicyChildren leaves the underlying loggers as they are,
+	// and the MultiLogger never exits or panics itself. The first
+	// logger that exits or panics prevents the following ones
+	// from writing the event.
+	ExitPolicyChildren
+)
+
+// MultiLogger implements the Logger interface by writing to
+// multiple underlying loggers sequentially, or concurrently
+// if Concurrent is set.
+//
+// To give each underlying logger its own levels or
+// filter, wrap it in a Route.
 type MultiLogger struct {
+	// Loggers are the underlying loggers. It must not be
+	// modified once the MultiLogger is in use. Add and
+	// Replace should be used to change it instead.
 	Loggers []Logger
+	Environment
+	Hooks
+
+	// Concurrent enables sending events to all the
+	// underlying loggers at the same time, so that
+	// a slow logger doesn't delay the others
+	Concurrent bool
+	// Timeout is the maximum amount of time to wait for each
+	// underlying logger when Concurrent is set. A logger that
+	// times out keeps sending the event in the background.
+	// Zero means no timeout.
+	Timeout time.Duration
+
+	// RecoverPanics enables recovering panics in the underlying
+	// loggers, so that they're reported to ErrorHandler rather
+	// than crashing the program. Panics that occur after a
+	// logger has timed out are always recovered.
+	RecoverPanics bool
+	// ErrorHandler is called with a ChildError when an
+	// underlying logger panics or times out
+	ErrorHandler func(error)
+
+	mu sync.RWMutex
+
+	// policy is the exit policy. It can only be set by
+	// NewMultiPolicy, since it's applied to the underlying
+	// loggers when they're added.
+	policy ExitPolicy
+
 	noPanic bool
 	noExit  bool
 }
 
 // NewMulti creates and returns a new MultiLogger
+// that uses ExitPolicyMulti
 func NewMulti(l ...Logger) *MultiLogger {
+	return NewMultiPolicy(ExitPolicyMulti, l...)
+}
+
+// NewMultiPolicy creates and returns a new MultiLogger that
+// uses the given exit policy. If the policy is ExitPolicyMulti,
+// exiting and panicking are disabled in the underlying loggers.
+func NewMultiPolicy(policy ExitPolicy, l ...Logger) *MultiLogger {
+	ml := &MultiLogger{Loggers: l, policy: policy}
 	for _, logger := range l {
-		logger.NoPanic()
-		logger.NoExit()
+		ml.applyPolicy(logger)
 	}
-	return &MultiLogger{Loggers: l}
+	return ml
 }
 
 // NoExit prevents the logger from exiting on fatal events
@@ -34,127 +118,228 @@ func (ml *MultiLogger) NoPanic() {
 	ml.noPanic = true
 }
 
-// SetLevel sets the log level of the logger
+// Add adds an underlying logger, applying the exit policy
+// to it, and returns a function that removes it. l must be
+// comparable, such as a pointer.
+//
+// The underlying loggers are copied rather than modified, so
+// events that have already been created aren't affected.
+func (ml *MultiLogger) Add(l Logger) (remove func()) {
+	ml.applyPolicy(l)
+
+	ml.mu.Lock()
+	loggers := make([]Logger, len(ml.Loggers), len(ml.Loggers)+1)
+	copy(loggers, ml.Loggers)
+	ml.Loggers = append(loggers, l)
+	ml.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { ml.remove(l) })
+	}
+}
+
+// Replace replaces all the underlying loggers, applying
+// the exit policy to the new ones. Events that have already
+// been created are still sent to the old loggers.
+func (ml *MultiLogger) Replace(l ...Logger) {
+	for _, logger := range l {
+		ml.applyPolicy(logger)
+	}
+
+	loggers := make([]Logger, len(l))
+	copy(loggers, l)
+
+	ml.mu.Lock()
+	ml.Loggers = loggers
+	ml.mu.Unlock()
+}
+
+// remove removes the first underlying logger equal to l
+func (ml *MultiLogger) remove(l Logger) {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+	for i, logger := range ml.Loggers {
+		if logger != l {
+			continue
+		}
+		loggers := make([]Logger, 0, len(ml.Loggers)-1)
+		loggers = append(loggers, ml.Loggers[:i]...)
+		ml.Loggers = append(loggers, ml.Loggers[i+1:]...)
+		return
+	}
+}
+
+// loggers returns the current underlying loggers.
+// The returned slice must not be modified.
+func (ml *MultiLogger) loggers() []Logger {
+	ml.mu.RLock()
+	defer ml.mu.RUnlock()
+	return ml.Loggers
+}
+
+// applyPolicy configures l according to the exit policy
+func (ml *MultiLogger) applyPolicy(l Logger) {
+	if ml.policy == ExitPolicyMulti {
+		l.NoPanic()
+		l.NoExit()
+	}
+}
+
+// exits reports whether the MultiLogger
+// itself exits on fatal events
+func (ml *MultiLogger) exits() bool {
+	return !ml.noExit && ml.policy == ExitPolicyMulti
+}
+
+// panics reports whether the MultiLogger
+// itself panics on panic events
+func (ml *MultiLogger) panics() bool {
+	return !ml.noPanic && ml.policy == ExitPolicyMulti
+}
+
+// reportError passes err to the error handler, if there is one
+func (ml *MultiLogger) reportError(err error) {
+	if ml.ErrorHandler != nil {
+		ml.ErrorHandler(err)
+	}
+}
+
+// SetLevel sets the log level of all the underlying loggers,
+// except for routes, which keep their own levels. Their
+// levels can be changed using Route.SetLevel.
 func (ml *MultiLogger) SetLevel(l LogLevel) {
-	for _, logger := range ml.Loggers {
+	for _, logger := range ml.loggers() {
+		if _, ok := logger.(*Route); ok {
+			continue
+		}
 		logger.SetLevel(l)
 	}
 }
 
+// Enabled reports whether any of the underlying loggers log
+// events with the given level. Fatal and panic events are
+// always enabled, since the MultiLogger may exit or panic,
+// and so is every level if any hooks have been added.
+func (ml *MultiLogger) Enabled(lvl LogLevel) bool {
+	if lvl >= LogLevelFatal || ml.hooked() {
+		return true
+	}
+	for _, logger := range ml.loggers() {
+		if enabled(logger, lvl) {
+			return true
+		}
+	}
+	return false
+}
+
 // Debug creates a new debug event with the given message
 func (ml *MultiLogger) Debug(msg string) LogBuilder {
-	lbs := make([]LogBuilder, len(ml.Loggers))
-	for index, logger := range ml.Loggers {
-		lbs[index] = logger.Debug(msg)
-	}
-	return &MultiLogBuilder{ml, lbs, LogLevelDebug}
+	return newMultiLogBuilder(ml, msg, LogLevelDebug)
 }
 
 // Debugf creates a new debug event with the formatted message
 func (ml *MultiLogger) Debugf(format string, v ...any) LogBuilder {
-	lbs := make([]LogBuilder, len(ml.Loggers))
-	for index, logger := range ml.Loggers {
-		lbs[index] = logger.Debugf(format, v...)
-	}
-	return &MultiLogBuilder{ml, lbs, LogLevelDebug}
+	return newMultiLogBuilder(ml, fmt.Sprintf(format, v...), LogLevelDebug)
 }
 
 // Info creates a new info event with the given message
 func (ml *MultiLogger) Info(msg string) LogBuilder {
-	lbs := make([]LogBuilder, len(ml.Loggers))
-	for index, logger := range ml.Loggers {
-		lbs[index] = logger.Info(msg)
-	}
-	return &MultiLogBuilder{ml, lbs, LogLevelInfo}
+	return newMultiLogBuilder(ml, msg, LogLevelInfo)
 }
 
 // Infof creates a new info event with the formatted message
 func (ml *MultiLogger) Infof(format string, v ...any) LogBuilder {
-	lbs := make([]LogBuilder, len(ml.Loggers))
-	for index, logger := range ml.Loggers {
-		lbs[index] = logger.Infof(format, v...)
-	}
-	return &MultiLogBuilder{ml, lbs, LogLevelInfo}
+	return newMultiLogBuilder(ml, fmt.Sprintf(format, v...), LogLevelInfo)
 }
 
 // Warn creates a new warn event with the given message
 func (ml *MultiLogger) Warn(msg string) LogBuilder {
-	lbs := make([]LogBuilder, len(ml.Loggers))
-	for index, logger := range ml.Loggers {
-		lbs[index] = logger.Warn(msg)
-	}
-	return &MultiLogBuilder{ml, lbs, LogLevelWarn}
+	return newMultiLogBuilder(ml, msg, LogLevelWarn)
 }
 
 // Warnf creates a new warn event with the formatted message
 func (ml *MultiLogger) Warnf(format string, v ...any) LogBuilder {
-	lbs := make([]LogBuilder, len(ml.Loggers))
-	for index, logger := range ml.Loggers {
-		lbs[index] = logger.Warnf(format, v...)
-	}
-	return &MultiLogBuilder{ml, lbs, LogLevelWarn}
+	return newMultiLogBuilder(ml, fmt.Sprintf(format, v...), LogLevelWarn)
 }
 
 // Error creates a new error event with the given message
 func (ml *MultiLogger) Error(msg string) LogBuilder {
-	lbs := make([]LogBuilder, len(ml.Loggers))
-	for index, logger := range ml.Loggers {
-		lbs[index] = logger.Error(msg)
-	}
-	return &MultiLogBuilder{ml, lbs, LogLevelError}
+	return newMultiLogBuilder(ml, msg, LogLevelError)
 }
 
 // Errorf creates a new error event with the formatted message
 func (ml *MultiLogger) Errorf(format string, v ...any) LogBuilder {
-	lbs := make([]LogBuilder, len(ml.Loggers))
-	for index, logger := range ml.Loggers {
-		lbs[index] = logger.Errorf(format, v...)
-	}
-	return &MultiLogBuilder{ml, lbs, LogLevelError}
+	return newMultiLogBuilder(ml, fmt.Sprintf(format, v...), LogLevelError)
 }
 
 // Error creates a new error event with the given message
 func (ml *MultiLogger) Fatal(msg string) LogBuilder {
-	lbs := make([]LogBuilder, len(ml.Loggers))
-	for index, logger := range ml.Loggers {
-		lbs[index] = logger.Fatal(msg)
-	}
-	return &MultiLogBuilder{ml, lbs, LogLevelFatal}
+	return newMultiLogBuilder(ml, msg, LogLevelFatal)
 }
 
 // Errorf creates a new error event with the formatted message
 func (ml *MultiLogger) Fatalf(format string, v ...any) LogBuilder {
-	lbs := make([]LogBuilder, len(ml.Loggers))
-	for index, logger := range ml.Loggers {
-		lbs[index] = logger.Fatalf(format, v...)
-	}
-	return &MultiLogBuilder{ml, lbs, LogLevelFatal}
+	return newMultiLogBuilder(ml, fmt.Sprintf(format, v...), LogLevelFatal)
 }
 
 // Error creates a new error event with the given message
 func (ml *MultiLogger) Panic(msg string) LogBuilder {
-	lbs := make([]LogBuilder, len(ml.Loggers))
-	for index, logger := range ml.Loggers {
-		lbs[index] = logger.Panic(msg)
-	}
-	return &MultiLogBuilder{ml, lbs, LogLevelPanic}
+	return newMultiLogBuilder(ml, msg, LogLevelPanic)
 }
 
 // Errorf creates a new error event with the formatted message
 func (ml *MultiLogger) Panicf(format string, v ...any) LogBuilder {
-	lbs := make([]LogBuilder, len(ml.Loggers))
-	for index, logger := range ml.Loggers {
-		lbs[index] = logger.Panicf(format, v...)
+	return newMultiLogBuilder(ml, fmt.Sprintf(format, v...), LogLevelPanic)
+}
+
+func newMultiLogBuilder(ml *MultiLogger, msg string, lvl LogLevel) LogBuilder {
+	if ml.hooked() {
+		return ml.withHooks(lvl, msg, ml.Now, func(lvl LogLevel, msg string) LogBuilder {
+			return startMultiLogBuilder(ml, msg, lvl)
+		})
 	}
-	return &MultiLogBuilder{ml, lbs, LogLevelPanic}
+	return startMultiLogBuilder(ml, msg, lvl)
+}
+
+// startMultiLogBuilder creates a new builder for each of the
+// underlying loggers, skipping any that are disabled at the
+// given level, such as routes that don't include it
+func startMultiLogBuilder(ml *MultiLogger, msg string, lvl LogLevel) LogBuilder {
+	loggers := ml.loggers()
+	mlb := &MultiLogBuilder{
+		l:   ml,
+		ls:  make([]Logger, 0, len(loggers)),
+		lbs: make([]LogBuilder, 0, len(loggers)),
+		lvl: lvl,
+	}
+	for _, logger := range loggers {
+		if !enabled(logger, lvl) {
+			continue
+		}
+		mlb.ls = append(mlb.ls, logger)
+		mlb.lbs = append(mlb.lbs, logBuilder(logger, lvl, msg))
+	}
+	if len(mlb.lbs) == 0 && lvl < LogLevelFatal {
+		return NopLogBuilder{}
+	}
+	// Collect the fields of panic events, so that the MultiLogger
+	// panics with a single PanicEvent rather than one for each
+	// underlying logger
+	if lvl == LogLevelPanic && ml.panics() {
+		return capturePanic(mlb, msg, ml.Now, &mlb.pe)
+	}
+	return mlb
 }
 
 // MultiLogBuilder implements the LogBuilder interface
-// by writing to multiple underlying LogBuilders sequentially.
+// by writing to multiple underlying LogBuilders.
 type MultiLogBuilder struct {
 	l   *MultiLogger
+	ls  []Logger
 	lbs []LogBuilder
 	lvl LogLevel
+	pe  *PanicEvent
 }
 
 // Int adds an int field to the output
@@ -317,12 +502,110 @@ func (mlb *MultiLogBuilder) Err(err error) LogBuilder {
 //
 // After calling send, do not use the event again.
 func (mlb *MultiLogBuilder) Send() {
-	for _, lb := range mlb.lbs {
-		lb.Send()
+	if mlb.l.Concurrent {
+		mlb.sendConcurrent()
+	} else {
+		for i, lb := range mlb.lbs {
+			mlb.sendChild(mlb.ls[i], lb)
+		}
 	}
-	if mlb.lvl == LogLevelFatal && !mlb.l.noExit {
-		os.Exit(1)
-	} else if mlb.lvl == LogLevelPanic && !mlb.l.noPanic {
-		panic("")
+	if mlb.lvl == LogLevelFatal && mlb.l.exits() {
+		mlb.l.exit()
+	} else if mlb.lvl == LogLevelPanic && mlb.l.panics() {
+		mlb.l.doPanic(mlb.pe)
 	}
 }
+
+// sendChild sends lb, recovering any panic if enabled
+func (mlb *MultiLogBuilder) sendChild(l Logger, lb LogBuilder) {
+	if !mlb.l.RecoverPanics {
+		lb.Send()
+		return
+	}
+	defer func() {
+		if v := recover(); v != nil {
+			mlb.l.reportError(childPanicError(l, v))
+		}
+	}()
+	lb.Send()
+}
+
+// childResult is the result of sending an event
+// to an underlying logger in a separate goroutine
+type childResult struct {
+	index int
+	panic any
+}
+
+// sendConcurrent sends each underlying builder in its own
+// goroutine and waits for them to finish or time out. If
+// an underlying logger panics and RecoverPanics isn't set,
+// the panic is re-raised once the others have finished.
+func (mlb *MultiLogBuilder) sendConcurrent() {
+	results := make(chan childResult, len(mlb.lbs))
+	for i, lb := range mlb.lbs {
+		go func(i int, lb LogBuilder) {
+			var v any
+			defer func() { results <- childResult{i, v} }()
+			defer func() { v = recover() }()
+			lb.Send()
+		}(i, lb)
+	}
+
+	var timeout <-chan time.Time
+	if mlb.l.Timeout > 0 {
+		timer := time.NewTimer(mlb.l.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var repanic any
+	done := make([]bool, len(mlb.lbs))
+wait:
+	for remaining := len(mlb.lbs); remaining > 0; remaining-- {
+		select {
+		case res := <-results:
+			done[res.index] = true
+			if res.panic == nil {
+				continue
+			}
+			if mlb.l.RecoverPanics {
+				mlb.l.reportError(childPanicError(mlb.ls[res.index], res.panic))
+			} else if repanic == nil {
+				repanic = res.panic
+			}
+		case <-timeout:
+			for i := range done {
+				if !done[i] {
+					mlb.l.reportError(&ChildError{Logger: mlb.ls[i], Err: ErrChildTimeout})
+				}
+			}
+			go mlb.drain(results, remaining)
+			break wait
+		}
+	}
+
+	if repanic != nil {
+		panic(repanic)
+	}
+}
+
+// drain waits for the remaining results after a timeout
+// and reports any panics that occur
+func (mlb *MultiLogBuilder) drain(results <-chan childResult, remaining int) {
+	for ; remaining > 0; remaining-- {
+		res := <-results
+		if res.panic != nil {
+			mlb.l.reportError(childPanicError(mlb.ls[res.index], res.panic))
+		}
+	}
+}
+
+// childPanicError returns a ChildError for a panic
+// that occurred in an underlying logger
+func childPanicError(l Logger, v any) error {
+	if err, ok := v.(error); ok {
+		return &ChildError{Logger: l, Err: fmt.Errorf("panic: %w", err)}
+	}
+	return &ChildError{Logger: l, Err: fmt.Errorf("panic: %v", v)}
+}
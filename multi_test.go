@@ -0,0 +1,308 @@
+package logger_test
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.elara.ws/logger"
+	"go.elara.ws/logger/logtest"
+)
+
+var errChildFailed = errors.New("child failed")
+
+// behaviorRoute returns a route wrapping o that
+// runs fn on every event before it's sent
+func behaviorRoute(o *logtest.Observer, fn func()) *logger.Route {
+	r := logger.NewRoute(o, logger.LogLevelDebug, logger.LogLevelPanic)
+	r.Filter = func(*logger.Event) bool {
+		fn()
+		return true
+	}
+	return r
+}
+
+// errorRecorder collects errors passed to an error handler
+type errorRecorder struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (er *errorRecorder) handle(err error) {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	er.errs = append(er.errs, err)
+}
+
+func (er *errorRecorder) errors() []error {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	return append([]error(nil), er.errs...)
+}
+
+func TestMultiRecover(t *testing.T) {
+	t.Run("sequential", func(t *testing.T) {
+		rec := &errorRecorder{}
+		o := logtest.New()
+		ml := logger.NewMulti(
+			behaviorRoute(logtest.New(), func() { panic(errChildFailed) }),
+			o,
+		)
+		ml.RecoverPanics = true
+		ml.ErrorHandler = rec.handle
+
+		ml.Info("Test").Send()
+
+		o.AssertLogged(t, logger.LogLevelInfo, "Test")
+		errs := rec.errors()
+		if len(errs) != 1 {
+			t.Fatalf("got: %d errors, want: 1", len(errs))
+		}
+		var ce *logger.ChildError
+		if !errors.As(errs[0], &ce) || !errors.Is(errs[0], errChildFailed) {
+			t.Errorf("unexpected error: %v", errs[0])
+		}
+		if _, ok := ce.Logger.(*logger.Route); !ok {
+			t.Errorf("got: %T, want: *logger.Route", ce.Logger)
+		}
+	})
+
+	t.Run("not-recovered", func(t *testing.T) {
+		ml := logger.NewMulti(behaviorRoute(logtest.New(), func() { panic("test") }))
+
+		defer func() {
+			if v := recover(); v != "test" {
+				t.Errorf("got: %v, want: test", v)
+			}
+		}()
+		ml.Info("Test").Send()
+	})
+}
+
+func TestMultiConcurrent(t *testing.T) {
+	t.Run("timeout", func(t *testing.T) {
+		rec := &errorRecorder{}
+		release := make(chan struct{})
+		defer close(release)
+
+		fast := logtest.New()
+		ml := logger.NewMulti(
+			behaviorRoute(logtest.New(), func() { <-release }),
+			fast,
+		)
+		ml.Concurrent = true
+		ml.Timeout = 50 * time.Millisecond
+		ml.ErrorHandler = rec.handle
+
+		start := time.Now()
+		ml.Info("Test").Send()
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("send took %s", elapsed)
+		}
+
+		fast.AssertLogged(t, logger.LogLevelInfo, "Test")
+		errs := rec.errors()
+		if len(errs) != 1 || !errors.Is(errs[0], logger.ErrChildTimeout) {
+			t.Errorf("expected a timeout error, got: %v", errs)
+		}
+	})
+
+	t.Run("late-panic", func(t *testing.T) {
+		rec := &errorRecorder{}
+		release := make(chan struct{})
+
+		ml := logger.NewMulti(behaviorRoute(logtest.New(), func() {
+			<-release
+			panic(errChildFailed)
+		}))
+		ml.Concurrent = true
+		ml.Timeout = 10 * time.Millisecond
+		ml.ErrorHandler = rec.handle
+
+		ml.Info("Test").Send()
+		close(release)
+
+		deadline := time.Now().Add(time.Second)
+		for len(rec.errors()) < 2 && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		errs := rec.errors()
+		if len(errs) != 2 || !errors.Is(errs[0], logger.ErrChildTimeout) || !errors.Is(errs[1], errChildFailed) {
+			t.Errorf("expected a timeout and a panic error, got: %v", errs)
+		}
+	})
+
+	t.Run("repanic", func(t *testing.T) {
+		o := logtest.New()
+		ml := logger.NewMulti(
+			behaviorRoute(logtest.New(), func() { panic("test") }),
+			o,
+		)
+		ml.Concurrent = true
+
+		func() {
+			defer func() {
+				if v := recover(); v != "test" {
+					t.Errorf("got: %v, want: test", v)
+				}
+			}()
+			ml.Info("Test").Send()
+		}()
+		o.AssertLogged(t, logger.LogLevelInfo, "Test")
+	})
+
+	t.Run("fatal", func(t *testing.T) {
+		o1, o2 := logtest.New(), logtest.New()
+		ml := logger.NewMulti(o1, o2)
+		ml.Concurrent = true
+
+		exited := false
+		ml.ExitFunc = func(int) {
+			exited = true
+			if o1.Len() != 1 || o2.Len() != 1 {
+				t.Error("exited before all loggers wrote the event")
+			}
+		}
+
+		ml.Fatal("Test").Send()
+		if !exited {
+			t.Error("expected exit")
+		}
+	})
+}
+
+func TestMultiExitPolicy(t *testing.T) {
+	t.Run("multi", func(t *testing.T) {
+		jl := logger.NewJSON(&bytes.Buffer{})
+		jl.ExitFunc = func(int) { t.Error("underlying logger exited") }
+
+		ml := logger.NewMulti(jl)
+		code := 0
+		ml.ExitFunc = func(c int) { code = c }
+
+		ml.Fatal("Test").Send()
+		if code != 1 {
+			t.Errorf("got: %d, want: 1", code)
+		}
+	})
+
+	t.Run("children", func(t *testing.T) {
+		code := 0
+		jl := logger.NewJSON(&bytes.Buffer{})
+		jl.ExitFunc = func(c int) { code = c }
+
+		ml := logger.NewMultiPolicy(logger.ExitPolicyChildren, jl)
+		ml.ExitFunc = func(int) { t.Error("MultiLogger exited") }
+
+		ml.Fatal("Test").Send()
+		if code != 1 {
+			t.Errorf("got: %d, want: 1", code)
+		}
+	})
+
+	t.Run("children-panic", func(t *testing.T) {
+		var got any
+		jl := logger.NewJSON(&bytes.Buffer{})
+		jl.PanicFunc = func(v any) { got = v }
+
+		ml := logger.NewMultiPolicy(logger.ExitPolicyChildren, jl)
+		ml.PanicFunc = func(any) { t.Error("MultiLogger panicked") }
+
+		ml.Panic("Test").Send()
+		if _, ok := got.(*logger.PanicEvent); !ok {
+			t.Errorf("got: %T, want: *logger.PanicEvent", got)
+		}
+	})
+}
+
+func TestMultiAdd(t *testing.T) {
+	t.Run("add-remove", func(t *testing.T) {
+		o1, o2 := logtest.New(), logtest.New()
+		ml := logger.NewMulti(o1)
+
+		remove := ml.Add(o2)
+		ml.Info("Test 1").Send()
+		remove()
+		remove()
+		ml.Info("Test 2").Send()
+
+		if got, want := o1.Len(), 2; got != want {
+			t.Errorf("got: %d events, want: %d", got, want)
+		}
+		if got, want := o2.Len(), 1; got != want {
+			t.Errorf("got: %d events, want: %d", got, want)
+		}
+		o2.AssertNotLogged(t, logger.LogLevelInfo, "Test 2")
+	})
+
+	t.Run("policy", func(t *testing.T) {
+		jl := logger.NewJSON(&bytes.Buffer{})
+		jl.ExitFunc = func(int) { t.Error("added logger exited") }
+
+		ml := logger.NewMulti()
+		ml.ExitFunc = func(int) {}
+		ml.Add(jl)
+
+		ml.Fatal("Test").Send()
+	})
+
+	t.Run("in-flight", func(t *testing.T) {
+		o := logtest.New()
+		ml := logger.NewMulti()
+		remove := ml.Add(o)
+
+		lb := ml.Info("Test")
+		remove()
+		lb.Str("a", "b").Send()
+
+		o.AssertLogged(t, logger.LogLevelInfo, "Test", logtest.Str("a", "b"))
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		o1, o2 := logtest.New(), logtest.New()
+		ml := logger.NewMulti(o1)
+
+		lb := ml.Info("Test 1")
+		ml.Replace(o2)
+		lb.Send()
+		ml.Info("Test 2").Send()
+
+		if !o1.Logged(logger.LogLevelInfo, "Test 1") || o1.Len() != 1 {
+			t.Errorf("unexpected events: %v", o1.Events())
+		}
+		if !o2.Logged(logger.LogLevelInfo, "Test 2") || o2.Len() != 1 {
+			t.Errorf("unexpected events: %v", o2.Events())
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		o := logtest.New()
+		ml := logger.NewMulti(o)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					ml.Info("Test").Send()
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					remove := ml.Add(logtest.New())
+					ml.SetLevel(logger.LogLevelDebug)
+					remove()
+				}
+			}()
+		}
+		wg.Wait()
+
+		if got, want := o.Len(), 400; got != want {
+			t.Errorf("got: %d events, want: %d", got, want)
+		}
+	})
+}
@@ -0,0 +1,339 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var _ Logger = (*NamedLogger)(nil)
+
+// LevelRules maps logger names to levels. A name matches the rule
+// with the longest prefix of its dot-separated components, so the
+// rule for "db" applies to "db.pool" unless there's a rule for
+// "db.pool" itself. Names without a matching rule use the
+// default level.
+//
+// LevelRules implements flag.Value, so it can be set using a
+// flag as well as by parsing an environment variable.
+type LevelRules struct {
+	Default LogLevel
+	Levels  map[string]LogLevel
+}
+
+// ParseLevelRules parses a comma-separated list of rules, such as
+// "db=debug,http=warn,*=info". The name "*" sets the default level,
+// as does a level without a name. If there's no default, it's info.
+func ParseLevelRules(s string) (LevelRules, error) {
+	lr := LevelRules{Default: LogLevelInfo, Levels: map[string]LogLevel{}}
+	for _, rule := range strings.Split(s, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		name, lvlStr, ok := strings.Cut(rule, "=")
+		if !ok {
+			name, lvlStr = "*", name
+		}
+		name, lvlStr = strings.TrimSpace(name), strings.TrimSpace(lvlStr)
+		if name == "" {
+			return LevelRules{}, fmt.Errorf("invalid level rule %q: missing name", rule)
+		}
+
+		lvl, err := ParseLogLevel(lvlStr)
+		if err != nil {
+			return LevelRules{}, fmt.Errorf("invalid level rule %q: %w", rule, err)
+		}
+		if name == "*" {
+			lr.Default = lvl
+		} else {
+			lr.Levels[name] = lvl
+		}
+	}
+	return lr, nil
+}
+
+// Level returns the level for the given name
+func (lr LevelRules) Level(name string) LogLevel {
+	for name != "" {
+		if lvl, ok := lr.Levels[name]; ok {
+			return lvl
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return lr.Default
+}
+
+// String returns the rules in the format
+// accepted by ParseLevelRules
+func (lr LevelRules) String() string {
+	names := make([]string, 0, len(lr.Levels))
+	for name := range lr.Levels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sb := strings.Builder{}
+	for _, name := range names {
+		sb.WriteString(name)
+		sb.WriteByte('=')
+		sb.WriteString(lr.Levels[name].String())
+		sb.WriteByte(',')
+	}
+	sb.WriteString("*=")
+	sb.WriteString(lr.Default.String())
+	return sb.String()
+}
+
+// Set parses s and replaces the rules with the result
+func (lr *LevelRules) Set(s string) error {
+	parsed, err := ParseLevelRules(s)
+	if err != nil {
+		return err
+	}
+	*lr = parsed
+	return nil
+}
+
+// NamedLogger implements the Logger interface by adding its name
+// to events and passing them to an underlying logger if they're at
+// or above the level for its name. Child loggers are created using
+// Named, so NewNamed(l, rules).Named("db").Named("pool") creates
+// a logger named "db.pool".
+//
+// A logger and all the loggers derived from it share the same
+// rules, and each logger resolves its level from them on every
+// call, so changing the rules with SetRules affects existing
+// children as well. SetLevel overrides the rules for a single
+// logger.
+//
+// The name is added as a field with the key "logger", or as a
+// prefix of the message if Prefix is set.
+type NamedLogger struct {
+	Logger Logger
+	Name   string
+	// Prefix enables adding the name to the beginning of
+	// the message, such as "[db.pool] message", rather
+	// than as a field
+	Prefix bool
+
+	rules *namedRules
+	// level is the level set with SetLevel plus one,
+	// or zero if the level is resolved from the rules
+	level uint32
+}
+
+// namedRules holds the rules shared by a
+// NamedLogger and the loggers derived from it
+type namedRules struct {
+	v atomic.Value
+}
+
+// NewNamed creates and returns a new unnamed NamedLogger wrapping l
+// that uses the given rules for its children's levels. The rules
+// must not be modified afterwards; use SetRules to replace them.
+//
+// NewNamed sets the level of l to debug, so that l doesn't filter out
+// events that the rules enable. This changes l itself, so if l is
+// used elsewhere, such as by the global logger, it'll log debug
+// events there too. To avoid that, pass a separate logger or wrap
+// l using NewLeveled first.
+//
+// Prefix is set if l is a *PrettyLogger or *CLILogger. Loggers
+// wrapped in another logger, such as a MultiLogger or
+// LeveledLogger, aren't detected, so set Prefix
+// explicitly for those.
+func NewNamed(l Logger, rules LevelRules) *NamedLogger {
+	l.SetLevel(LogLevelDebug)
+
+	prefix := false
+	switch l.(type) {
+	case *PrettyLogger, *CLILogger:
+		prefix = true
+	}
+
+	nl := &NamedLogger{
+		Logger: l,
+		Prefix: prefix,
+		rules:  &namedRules{},
+	}
+	nl.rules.v.Store(rules)
+	return nl
+}
+
+// Named returns a child logger whose name is the given name
+// appended to the logger's name, separated by a dot. Its level
+// is resolved from the rules, even if the parent's level
+// was set using SetLevel.
+func (nl *NamedLogger) Named(name string) *NamedLogger {
+	if nl.Name != "" {
+		name = nl.Name + "." + name
+	}
+	return &NamedLogger{
+		Logger: nl.Logger,
+		Name:   name,
+		Prefix: nl.Prefix,
+		rules:  nl.rules,
+	}
+}
+
+// Rules returns the rules used to resolve the logger's level
+func (nl *NamedLogger) Rules() LevelRules {
+	if nl.rules == nil {
+		return LevelRules{}
+	}
+	return nl.rules.v.Load().(LevelRules)
+}
+
+// SetRules replaces the rules of the logger and of all the loggers
+// created from the same NewNamed call, including existing ones.
+// Levels set with SetLevel still take precedence. The rules must
+// not be modified afterwards.
+func (nl *NamedLogger) SetRules(rules LevelRules) {
+	if nl.rules == nil {
+		nl.rules = &namedRules{}
+	}
+	nl.rules.v.Store(rules)
+}
+
+// Level returns the current level of the logger
+func (nl *NamedLogger) Level() LogLevel {
+	if lvl := atomic.LoadUint32(&nl.level); lvl != 0 {
+		return LogLevel(lvl - 1)
+	}
+	return nl.Rules().Level(nl.Name)
+}
+
+// Enabled reports whether events with the given level are logged
+func (nl *NamedLogger) Enabled(lvl LogLevel) bool {
+	return lvl >= nl.Level() && enabled(nl.Logger, lvl)
+}
+
+// NoPanic prevents the logger from panicking on panic events
+func (nl *NamedLogger) NoPanic() {
+	nl.Logger.NoPanic()
+}
+
+// NoExit prevents the logger from exiting on fatal events
+func (nl *NamedLogger) NoExit() {
+	nl.Logger.NoExit()
+}
+
+// Now returns the current time using
+// the clock of the underlying logger
+func (nl *NamedLogger) Now() time.Time {
+	return clockOf(nl.Logger)()
+}
+
+// SetLevel sets the log level of the logger, overriding the
+// rules. It doesn't affect the logger's parent or children.
+func (nl *NamedLogger) SetLevel(l LogLevel) {
+	atomic.StoreUint32(&nl.level, uint32(l)+1)
+}
+
+// compareAndSwapLevel sets the level to new only if it's
+// currently old. A level resolved from the rules
+// is never swapped.
+func (nl *NamedLogger) compareAndSwapLevel(old, new LogLevel) bool {
+	return atomic.CompareAndSwapUint32(&nl.level, uint32(old)+1, uint32(new)+1)
+}
+
+// levelSet reports whether the level was set using SetLevel
+func (nl *NamedLogger) levelSet() bool {
+	return atomic.LoadUint32(&nl.level) != 0
+}
+
+// compareAndResetLevel makes the logger resolve its level from
+// the rules again only if its level is currently set to old
+func (nl *NamedLogger) compareAndResetLevel(old LogLevel) bool {
+	return atomic.CompareAndSwapUint32(&nl.level, uint32(old)+1, 0)
+}
+
+// Debug creates a new debug event with the given message
+func (nl *NamedLogger) Debug(msg string) LogBuilder {
+	return newNamedLogBuilder(nl, msg, LogLevelDebug)
+}
+
+// Debugf creates a new debug event with the formatted message
+func (nl *NamedLogger) Debugf(format string, v ...any) LogBuilder {
+	return newNamedLogBuilder(nl, fmt.Sprintf(format, v...), LogLevelDebug)
+}
+
+// Info creates a new info event with the given message
+func (nl *NamedLogger) Info(msg string) LogBuilder {
+	return newNamedLogBuilder(nl, msg, LogLevelInfo)
+}
+
+// Infof creates a new info event with the formatted message
+func (nl *NamedLogger) Infof(format string, v ...any) LogBuilder {
+	return newNamedLogBuilder(nl, fmt.Sprintf(format, v...), LogLevelInfo)
+}
+
+// Warn creates a new warn event with the given message
+func (nl *NamedLogger) Warn(msg string) LogBuilder {
+	return newNamedLogBuilder(nl, msg, LogLevelWarn)
+}
+
+// Warnf creates a new warn event with the formatted message
+func (nl *NamedLogger) Warnf(format string, v ...any) LogBuilder {
+	return newNamedLogBuilder(nl, fmt.Sprintf(format, v...), LogLevelWarn)
+}
+
+// Error creates a new error event with the given message
+func (nl *NamedLogger) Error(msg string) LogBuilder {
+	return newNamedLogBuilder(nl, msg, LogLevelError)
+}
+
+// Errorf creates a new error event with the formatted message
+func (nl *NamedLogger) Errorf(format string, v ...any) LogBuilder {
+	return newNamedLogBuilder(nl, fmt.Sprintf(format, v...), LogLevelError)
+}
+
+// Fatal creates a new fatal event with the given message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (nl *NamedLogger) Fatal(msg string) LogBuilder {
+	return newNamedLogBuilder(nl, msg, LogLevelFatal)
+}
+
+// Fatalf creates a new fatal event with the formatted message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (nl *NamedLogger) Fatalf(format string, v ...any) LogBuilder {
+	return newNamedLogBuilder(nl, fmt.Sprintf(format, v...), LogLevelFatal)
+}
+
+// Panic creates a new panic event with the given message
+//
+// When sent, panic events will cause a panic
+func (nl *NamedLogger) Panic(msg string) LogBuilder {
+	return newNamedLogBuilder(nl, msg, LogLevelPanic)
+}
+
+// Panicf creates a new panic event with the formatted message
+//
+// When sent, panic events will cause a panic
+func (nl *NamedLogger) Panicf(format string, v ...any) LogBuilder {
+	return newNamedLogBuilder(nl, fmt.Sprintf(format, v...), LogLevelPanic)
+}
+
+func newNamedLogBuilder(nl *NamedLogger, msg string, lvl LogLevel) LogBuilder {
+	if !nl.Enabled(lvl) {
+		return NopLogBuilder{}
+	}
+	switch {
+	case nl.Name == "":
+		return logBuilder(nl.Logger, lvl, msg)
+	case nl.Prefix:
+		return logBuilder(nl.Logger, lvl, "["+nl.Name+"] "+msg)
+	default:
+		return logBuilder(nl.Logger, lvl, msg).Str("logger", nl.Name)
+	}
+}
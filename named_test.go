@@ -0,0 +1,180 @@
+package logger_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+
+	"go.elara.ws/logger"
+	"go.elara.ws/logger/logtest"
+)
+
+func TestParseLevelRules(t *testing.T) {
+	lr, err := logger.ParseLevelRules("db=debug, http=warn,db.pool=error,*=info")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		lvl  logger.LogLevel
+	}{
+		{"", logger.LogLevelInfo},
+		{"db", logger.LogLevelDebug},
+		{"db.query", logger.LogLevelDebug},
+		{"db.pool", logger.LogLevelError},
+		{"db.pool.conn", logger.LogLevelError},
+		{"dbx", logger.LogLevelInfo},
+		{"http", logger.LogLevelWarn},
+		{"grpc", logger.LogLevelInfo},
+	}
+	for _, test := range tests {
+		if got := lr.Level(test.name); got != test.lvl {
+			t.Errorf("%q: got: %d, want: %d", test.name, got, test.lvl)
+		}
+	}
+
+	expected := "db=debug,db.pool=error,http=warn,*=info"
+	if lr.String() != expected {
+		t.Errorf("got: %s, want: %s", lr.String(), expected)
+	}
+
+	t.Run("default", func(t *testing.T) {
+		lr, err := logger.ParseLevelRules("debug")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lr.Default != logger.LogLevelDebug {
+			t.Errorf("got: %d, want: %d", lr.Default, logger.LogLevelDebug)
+		}
+
+		lr, err = logger.ParseLevelRules("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lr.Default != logger.LogLevelInfo {
+			t.Errorf("got: %d, want: %d", lr.Default, logger.LogLevelInfo)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := logger.ParseLevelRules("db=verbose"); !errors.Is(err, logger.ErrNoSuchLevel) {
+			t.Errorf("got: %v, want: %v", err, logger.ErrNoSuchLevel)
+		}
+		if _, err := logger.ParseLevelRules("=debug"); err == nil {
+			t.Error("expected error for rule without name")
+		}
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		var lr logger.LevelRules
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.Var(&lr, "log-level", "")
+		if err := fs.Parse([]string{"-log-level", "http=debug,*=error"}); err != nil {
+			t.Fatal(err)
+		}
+		if lr.Level("http.server") != logger.LogLevelDebug || lr.Default != logger.LogLevelError {
+			t.Errorf("unexpected rules: %s", lr)
+		}
+	})
+}
+
+func TestNamed(t *testing.T) {
+	rules, err := logger.ParseLevelRules("db=debug,http=warn,*=info")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("field", func(t *testing.T) {
+		o := logtest.New()
+		root := logger.NewNamed(o, rules)
+		pool := root.Named("db").Named("pool")
+		http := root.Named("http")
+
+		root.Debug("root debug").Send()
+		root.Info("root info").Send()
+		pool.Debugf("pool %s", "debug").Send()
+		http.Info("http info").Send()
+		http.Warn("http warn").Send()
+
+		if got, want := o.Len(), 3; got != want {
+			t.Fatalf("got: %d events, want: %d: %v", got, want, o.Events())
+		}
+		if pool.Name != "db.pool" {
+			t.Errorf("got: %s, want: db.pool", pool.Name)
+		}
+		o.AssertLogged(t, logger.LogLevelInfo, "root info")
+		o.AssertLogged(t, logger.LogLevelDebug, "pool debug", logtest.Str("logger", "db.pool"))
+		o.AssertLogged(t, logger.LogLevelWarn, "http warn", logtest.Str("logger", "http"))
+		for _, f := range o.FilterMessage("root info")[0].Fields {
+			if f.Key == "logger" {
+				t.Error("unexpected logger field on the root logger")
+			}
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		db := logger.NewNamed(logger.NewJSON(buf), rules).Named("db")
+
+		db.Debug("Test").Send()
+
+		expected := `{"msg":"Test","level":"debug","logger":"db"}`
+		if buf.String() != expected {
+			t.Errorf("got: %s, want: %s", buf.String(), expected)
+		}
+	})
+
+	t.Run("pretty-prefix", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		pool := logger.NewNamed(logger.NewPretty(buf), rules).Named("db").Named("pool")
+
+		pool.Info("Test").Send()
+
+		if !strings.Contains(buf.String(), "[db.pool] Test") || strings.Contains(buf.String(), "logger=") {
+			t.Errorf("expected name prefix, got: %s", buf.String())
+		}
+	})
+
+	t.Run("set-level", func(t *testing.T) {
+		o := logtest.New()
+		root := logger.NewNamed(o, rules)
+		http := root.Named("http")
+		http.SetLevel(logger.LogLevelDebug)
+
+		http.Debug("Test").Send()
+		root.Named("http").Debug("Test 2").Send()
+
+		o.AssertLogged(t, logger.LogLevelDebug, "Test")
+		o.AssertNotLogged(t, logger.LogLevelDebug, "Test 2")
+	})
+
+	t.Run("set-rules", func(t *testing.T) {
+		o := logtest.New()
+		root := logger.NewNamed(o, rules)
+		db := root.Named("db")
+		http := root.Named("http")
+		http.SetLevel(logger.LogLevelError)
+
+		newRules, err := logger.ParseLevelRules("db=warn,*=debug")
+		if err != nil {
+			t.Fatal(err)
+		}
+		root.SetRules(newRules)
+
+		db.Info("db info").Send()
+		db.Warn("db warn").Send()
+		http.Warn("http warn").Send()
+		root.Named("grpc").Debug("grpc debug").Send()
+
+		o.AssertNotLogged(t, logger.LogLevelInfo, "db info")
+		o.AssertLogged(t, logger.LogLevelWarn, "db warn")
+		o.AssertNotLogged(t, logger.LogLevelWarn, "http warn")
+		o.AssertLogged(t, logger.LogLevelDebug, "grpc debug")
+		if got := db.Rules().String(); got != newRules.String() {
+			t.Errorf("got: %s, want: %s", got, newRules.String())
+		}
+	})
+}
@@ -0,0 +1,306 @@
+package logger
+
+import (
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ io.WriteCloser = (*NetWriter)(nil)
+
+// ErrWriterClosed is returned when writing to a closed writer
+var ErrWriterClosed = errors.New("writer closed")
+
+// NetWriterStats contains statistics about a NetWriter
+type NetWriterStats struct {
+	// Written is the amount of events written to the connection
+	Written uint64
+	// Buffered is the amount of events currently
+	// buffered while disconnected
+	Buffered uint64
+	// Dropped is the amount of events dropped
+	// because the buffer was full
+	Dropped uint64
+	// Reconnects is the amount of times the
+	// connection was re-established
+	Reconnects uint64
+	// Errors is the amount of connection errors
+	Errors uint64
+}
+
+// NetWriter is an io.Writer that sends newline-delimited events
+// over a TCP, TLS, UDP or Unix socket connection. It's safe for
+// concurrent use, so it can be shared between several loggers.
+//
+// When the connection fails, NetWriter reconnects in the
+// background with exponential backoff. Events written while
+// disconnected are kept in a bounded buffer and sent in order
+// once the connection is re-established. If the buffer is full,
+// the oldest events are dropped.
+type NetWriter struct {
+	// TLSConfig is used when the network is "tls"
+	TLSConfig *tls.Config
+
+	// DialTimeout is the timeout for establishing a connection
+	DialTimeout time.Duration
+	// WriteTimeout is the timeout for writing a single event
+	WriteTimeout time.Duration
+
+	// InitialBackoff is the time to wait before the first reconnect.
+	// It doubles after every failed attempt, up to MaxBackoff.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum time to wait between reconnects
+	MaxBackoff time.Duration
+
+	// BufferSize is the maximum amount of events
+	// kept in memory while disconnected
+	BufferSize int
+
+	// OnError is called with any connection errors. It must
+	// not write to the NetWriter, or a deadlock will occur.
+	OnError func(error)
+
+	network string
+	addr    string
+
+	mu            sync.Mutex
+	conn          net.Conn
+	queue         [][]byte
+	stats         NetWriterStats
+	connected     bool
+	everConnected bool
+	reconnecting  bool
+	closed        bool
+	done          chan struct{}
+}
+
+// NewNetWriter creates and returns a new NetWriter that sends
+// events to the given address. The network may be any network
+// supported by net.Dial, or "tls" for TLS over TCP. The
+// connection is established in the background on first write.
+func NewNetWriter(network, addr string) *NetWriter {
+	return &NetWriter{
+		DialTimeout:    10 * time.Second,
+		WriteTimeout:   10 * time.Second,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		BufferSize:     1000,
+		network:        network,
+		addr:           addr,
+		done:           make(chan struct{}),
+	}
+}
+
+// Write sends p as a single event, adding a newline if needed.
+// If the writer is disconnected, the event is buffered
+// and no error is returned.
+func (nw *NetWriter) Write(p []byte) (int, error) {
+	msg := make([]byte, len(p), len(p)+1)
+	copy(msg, p)
+	if len(msg) == 0 || msg[len(msg)-1] != '\n' {
+		msg = append(msg, '\n')
+	}
+
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+
+	if nw.closed {
+		return 0, ErrWriterClosed
+	}
+
+	if nw.connected {
+		err := nw.writeConn(msg)
+		if err == nil {
+			return len(p), nil
+		}
+		nw.disconnect(err)
+	}
+
+	nw.enqueue(msg)
+	nw.startReconnect()
+	return len(p), nil
+}
+
+// Stats returns statistics about the writer
+func (nw *NetWriter) Stats() NetWriterStats {
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+	stats := nw.stats
+	stats.Buffered = uint64(len(nw.queue))
+	return stats
+}
+
+// Close closes the connection and stops reconnecting.
+// Any buffered events are discarded.
+func (nw *NetWriter) Close() error {
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+
+	if nw.closed {
+		return nil
+	}
+	nw.closed = true
+	close(nw.done)
+	nw.queue = nil
+
+	if nw.conn != nil {
+		nw.connected = false
+		return nw.conn.Close()
+	}
+	return nil
+}
+
+// writeConn writes msg to the current connection.
+// The mutex must be held.
+func (nw *NetWriter) writeConn(msg []byte) error {
+	if nw.WriteTimeout > 0 {
+		nw.conn.SetWriteDeadline(time.Now().Add(nw.WriteTimeout))
+	}
+	_, err := nw.conn.Write(msg)
+	if err == nil {
+		nw.stats.Written++
+	}
+	return err
+}
+
+// enqueue adds msg to the buffer, dropping the oldest
+// event if it's full. The mutex must be held.
+func (nw *NetWriter) enqueue(msg []byte) {
+	if nw.BufferSize <= 0 {
+		nw.stats.Dropped++
+		return
+	}
+	if len(nw.queue) >= nw.BufferSize {
+		nw.queue = nw.queue[1:]
+		nw.stats.Dropped++
+	}
+	nw.queue = append(nw.queue, msg)
+}
+
+// disconnect closes the current connection and reports err.
+// The mutex must be held.
+func (nw *NetWriter) disconnect(err error) {
+	nw.connected = false
+	nw.conn.Close()
+	nw.reportError(err)
+}
+
+// reportError counts err and passes it to OnError.
+// The mutex must be held.
+func (nw *NetWriter) reportError(err error) {
+	nw.stats.Errors++
+	if nw.OnError != nil {
+		nw.OnError(err)
+	}
+}
+
+// startReconnect starts the reconnect loop if it's not
+// already running. The mutex must be held.
+func (nw *NetWriter) startReconnect() {
+	if nw.reconnecting || nw.closed {
+		return
+	}
+	nw.reconnecting = true
+	go nw.reconnect()
+}
+
+// reconnect tries to connect until it succeeds or the
+// writer is closed, then sends any buffered events
+func (nw *NetWriter) reconnect() {
+	backoff := nw.InitialBackoff
+
+	for {
+		conn, err := nw.dial()
+
+		nw.mu.Lock()
+		if nw.closed {
+			if conn != nil {
+				conn.Close()
+			}
+			nw.mu.Unlock()
+			return
+		}
+
+		if err == nil {
+			nw.conn = conn
+			nw.connected = true
+			if nw.everConnected {
+				nw.stats.Reconnects++
+			}
+			nw.everConnected = true
+
+			err = nw.flushQueue()
+			if err == nil {
+				nw.reconnecting = false
+				nw.mu.Unlock()
+				go nw.watch(conn)
+				return
+			}
+			nw.disconnect(err)
+		} else {
+			nw.reportError(err)
+		}
+		nw.mu.Unlock()
+
+		select {
+		case <-time.After(backoff):
+		case <-nw.done:
+			return
+		}
+		backoff *= 2
+		if backoff > nw.MaxBackoff {
+			backoff = nw.MaxBackoff
+		}
+	}
+}
+
+// flushQueue writes all buffered events to the current
+// connection. The mutex must be held.
+func (nw *NetWriter) flushQueue() error {
+	for len(nw.queue) > 0 {
+		if err := nw.writeConn(nw.queue[0]); err != nil {
+			return err
+		}
+		nw.queue = nw.queue[1:]
+	}
+	nw.queue = nil
+	return nil
+}
+
+// dial establishes a new connection
+func (nw *NetWriter) dial() (net.Conn, error) {
+	d := &net.Dialer{Timeout: nw.DialTimeout}
+	if nw.network == "tls" {
+		return tls.DialWithDialer(d, "tcp", nw.addr, nw.TLSConfig)
+	}
+	return d.Dial(nw.network, nw.addr)
+}
+
+// watch reads from stream connections to detect when the
+// server closes them, so that the writer can reconnect
+// before events are lost.
+func (nw *NetWriter) watch(conn net.Conn) {
+	if strings.HasPrefix(nw.network, "udp") || strings.HasPrefix(nw.network, "ip") || nw.network == "unixgram" {
+		return
+	}
+
+	buf := make([]byte, 1)
+	for {
+		if _, err := conn.Read(buf); err != nil {
+			nw.mu.Lock()
+			if nw.conn == conn && nw.connected {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				nw.disconnect(err)
+				nw.startReconnect()
+			}
+			nw.mu.Unlock()
+			return
+		}
+	}
+}
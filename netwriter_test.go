@@ -0,0 +1,175 @@
+package logger_test
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"go.elara.ws/logger"
+)
+
+// lineServer is a TCP server that records the lines it receives
+type lineServer struct {
+	ln    net.Listener
+	mu    sync.Mutex
+	conns []net.Conn
+	lines chan string
+}
+
+func newLineServer(t *testing.T, addr string) *lineServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ls := &lineServer{ln: ln, lines: make(chan string, 100)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			ls.mu.Lock()
+			ls.conns = append(ls.conns, conn)
+			ls.mu.Unlock()
+			go func() {
+				sc := bufio.NewScanner(conn)
+				for sc.Scan() {
+					ls.lines <- sc.Text()
+				}
+			}()
+		}
+	}()
+	t.Cleanup(ls.Close)
+	return ls
+}
+
+// Close closes the listener and all connections
+func (ls *lineServer) Close() {
+	ls.ln.Close()
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	for _, conn := range ls.conns {
+		conn.Close()
+	}
+}
+
+func (ls *lineServer) next(t *testing.T) string {
+	t.Helper()
+	select {
+	case line := <-ls.lines:
+		return line
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for line")
+		return ""
+	}
+}
+
+func TestNetWriter(t *testing.T) {
+	t.Run("send", func(t *testing.T) {
+		ls := newLineServer(t, "127.0.0.1:0")
+		nw := logger.NewNetWriter("tcp", ls.ln.Addr().String())
+		defer nw.Close()
+
+		jsonlog := logger.NewJSON(nw)
+		jsonlog.Info("One").Send()
+		jsonlog.Info("Two").Send()
+
+		if got, want := ls.next(t), `{"msg":"One","level":"info"}`; got != want {
+			t.Errorf("got: %s, want: %s", got, want)
+		}
+		if got, want := ls.next(t), `{"msg":"Two","level":"info"}`; got != want {
+			t.Errorf("got: %s, want: %s", got, want)
+		}
+	})
+
+	t.Run("buffer", func(t *testing.T) {
+		// Reserve an address without listening on it
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr := ln.Addr().String()
+		ln.Close()
+
+		var errMu sync.Mutex
+		var errs int
+		nw := logger.NewNetWriter("tcp", addr)
+		nw.InitialBackoff = 10 * time.Millisecond
+		nw.MaxBackoff = 10 * time.Millisecond
+		nw.BufferSize = 3
+		nw.OnError = func(error) {
+			errMu.Lock()
+			errs++
+			errMu.Unlock()
+		}
+		defer nw.Close()
+
+		for i := 0; i < 5; i++ {
+			nw.Write([]byte(strconv.Itoa(i)))
+		}
+
+		stats := nw.Stats()
+		if stats.Dropped != 2 {
+			t.Errorf("expected 2 dropped events, got %d", stats.Dropped)
+		}
+		if stats.Buffered != 3 {
+			t.Errorf("expected 3 buffered events, got %d", stats.Buffered)
+		}
+
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			errMu.Lock()
+			n := errs
+			errMu.Unlock()
+			if n > 0 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("expected connection errors to be reported")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		ls := newLineServer(t, addr)
+		for _, want := range []string{"2", "3", "4"} {
+			if got := ls.next(t); got != want {
+				t.Errorf("got: %s, want: %s", got, want)
+			}
+		}
+	})
+
+	t.Run("reconnect", func(t *testing.T) {
+		ls := newLineServer(t, "127.0.0.1:0")
+		addr := ls.ln.Addr().String()
+
+		nw := logger.NewNetWriter("tcp", addr)
+		nw.InitialBackoff = 10 * time.Millisecond
+		nw.MaxBackoff = 10 * time.Millisecond
+		defer nw.Close()
+
+		nw.Write([]byte("before"))
+		if got, want := ls.next(t), "before"; got != want {
+			t.Errorf("got: %s, want: %s", got, want)
+		}
+
+		ls.Close()
+		ls = newLineServer(t, addr)
+
+		deadline := time.Now().Add(5 * time.Second)
+		for nw.Stats().Reconnects == 0 {
+			if time.Now().After(deadline) {
+				t.Fatal("timed out waiting for reconnect")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		nw.Write([]byte("after"))
+		if got, want := ls.next(t), "after"; got != want {
+			t.Errorf("got: %s, want: %s", got, want)
+		}
+	})
+}
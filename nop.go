@@ -24,6 +24,12 @@ func (nl NopLogger) NoExit() {}
 // SetLevel sets the log level of the logger
 func (nl NopLogger) SetLevel(LogLevel) {}
 
+// Enabled reports whether events with the given level
+// are logged, which is never the case for NopLogger
+func (nl NopLogger) Enabled(LogLevel) bool {
+	return false
+}
+
 // Debug creates a new debug event with the given message
 func (nl NopLogger) Debug(msg string) LogBuilder {
 	return NopLogBuilder{}
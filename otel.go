@@ -0,0 +1,362 @@
+package logger
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ Logger = (*OTelLogger)(nil)
+
+// otelSeverities maps log levels to OpenTelemetry severity numbers
+var otelSeverities = [...]int{
+	LogLevelDebug: 5,
+	LogLevelInfo:  9,
+	LogLevelWarn:  13,
+	LogLevelError: 17,
+	LogLevelFatal: 21,
+	LogLevelPanic: 22,
+}
+
+// OTelLogger implements the Logger interface by converting
+// events to the OpenTelemetry logs data model and passing
+// them to an OTLPExporter.
+type OTelLogger struct {
+	Exporter *OTLPExporter
+	Level    LogLevel
+
+	// TraceIDKey is the key of the field containing the trace ID.
+	// The field may be a hex-encoded string or 16 bytes.
+	TraceIDKey string
+	// SpanIDKey is the key of the field containing the span ID.
+	// The field may be a hex-encoded string or 8 bytes.
+	SpanIDKey string
+
+	Environment
+
+	noPanic bool
+	noExit  bool
+}
+
+// NewOTel creates and returns a new OTelLogger
+func NewOTel(exp *OTLPExporter) *OTelLogger {
+	return &OTelLogger{
+		Exporter:   exp,
+		Level:      LogLevelInfo,
+		TraceIDKey: "trace_id",
+		SpanIDKey:  "span_id",
+	}
+}
+
+// NoPanic prevents the logger from panicking on panic events
+func (ol *OTelLogger) NoPanic() {
+	ol.noPanic = true
+}
+
+// NoExit prevents the logger from exiting on fatal events
+func (ol *OTelLogger) NoExit() {
+	ol.noExit = true
+}
+
+// SetLevel sets the log level of the logger
+func (ol *OTelLogger) SetLevel(l LogLevel) {
+	ol.Level = l
+}
+
+// Enabled reports whether events with the given level are logged
+func (ol *OTelLogger) Enabled(lvl LogLevel) bool {
+	return ol.Exporter != nil && lvl >= ol.Level
+}
+
+// Debug creates a new debug event with the given message
+func (ol *OTelLogger) Debug(msg string) LogBuilder {
+	return newOTelLogBuilder(ol, msg, LogLevelDebug)
+}
+
+// Debugf creates a new debug event with the formatted message
+func (ol *OTelLogger) Debugf(format string, v ...any) LogBuilder {
+	return newOTelLogBuilder(ol, fmt.Sprintf(format, v...), LogLevelDebug)
+}
+
+// Info creates a new info event with the given message
+func (ol *OTelLogger) Info(msg string) LogBuilder {
+	return newOTelLogBuilder(ol, msg, LogLevelInfo)
+}
+
+// Infof creates a new info event with the formatted message
+func (ol *OTelLogger) Infof(format string, v ...any) LogBuilder {
+	return newOTelLogBuilder(ol, fmt.Sprintf(format, v...), LogLevelInfo)
+}
+
+// Warn creates a new warn event with the given message
+func (ol *OTelLogger) Warn(msg string) LogBuilder {
+	return newOTelLogBuilder(ol, msg, LogLevelWarn)
+}
+
+// Warnf creates a new warn event with the formatted message
+func (ol *OTelLogger) Warnf(format string, v ...any) LogBuilder {
+	return newOTelLogBuilder(ol, fmt.Sprintf(format, v...), LogLevelWarn)
+}
+
+// Error creates a new error event with the given message
+func (ol *OTelLogger) Error(msg string) LogBuilder {
+	return newOTelLogBuilder(ol, msg, LogLevelError)
+}
+
+// Errorf creates a new error event with the formatted message
+func (ol *OTelLogger) Errorf(format string, v ...any) LogBuilder {
+	return newOTelLogBuilder(ol, fmt.Sprintf(format, v...), LogLevelError)
+}
+
+// Fatal creates a new fatal event with the given message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (ol *OTelLogger) Fatal(msg string) LogBuilder {
+	return newOTelLogBuilder(ol, msg, LogLevelFatal)
+}
+
+// Fatalf creates a new fatal event with the formatted message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (ol *OTelLogger) Fatalf(format string, v ...any) LogBuilder {
+	return newOTelLogBuilder(ol, fmt.Sprintf(format, v...), LogLevelFatal)
+}
+
+// Panic creates a new panic event with the given message
+//
+// When sent, panic events will cause a panic
+func (ol *OTelLogger) Panic(msg string) LogBuilder {
+	return newOTelLogBuilder(ol, msg, LogLevelPanic)
+}
+
+// Panicf creates a new panic event with the formatted message
+//
+// When sent, panic events will cause a panic
+func (ol *OTelLogger) Panicf(format string, v ...any) LogBuilder {
+	return newOTelLogBuilder(ol, fmt.Sprintf(format, v...), LogLevelPanic)
+}
+
+func newOTelLogBuilder(ol *OTelLogger, msg string, lvl LogLevel) LogBuilder {
+	if !ol.Enabled(lvl) {
+		return NopLogBuilder{}
+	}
+	return newEventLogBuilder(lvl, msg, ol.Now, ol.send)
+}
+
+// LogEvent logs a collected event, keeping its time
+func (ol *OTelLogger) LogEvent(e *Event) {
+	if ol.Enabled(e.Level) {
+		ol.send(e)
+	}
+}
+
+// send converts the event to a log record and exports it.
+// Fatal and panic events are flushed immediately so that
+// they aren't lost when the program terminates. Errors
+// are passed to the exporter's error handler.
+func (ol *OTelLogger) send(e *Event) {
+	ol.Exporter.handleError(ol.Exporter.Export(ol.Record(e)))
+
+	if e.Level == LogLevelFatal && !ol.noExit {
+		ol.Exporter.handleError(ol.Exporter.Flush())
+		ol.exit()
+	} else if e.Level == LogLevelPanic && !ol.noPanic {
+		ol.Exporter.handleError(ol.Exporter.Flush())
+		ol.doPanic(NewPanicEvent(e))
+	}
+}
+
+// Record converts an event to an OpenTelemetry log record
+func (ol *OTelLogger) Record(e *Event) OTelLogRecord {
+	ts := strconv.FormatInt(e.Time.UnixNano(), 10)
+	rec := OTelLogRecord{
+		TimeUnixNano:         ts,
+		ObservedTimeUnixNano: ts,
+		SeverityNumber:       otelSeverities[e.Level],
+		SeverityText:         strings.ToUpper(e.Level.String()),
+		Body:                 OTelString(e.Message),
+	}
+
+	for _, f := range e.Fields {
+		switch {
+		case f.Key == ol.TraceIDKey && f.Kind != KindError:
+			rec.TraceID = otelID(f, 16)
+			if rec.TraceID != "" {
+				continue
+			}
+		case f.Key == ol.SpanIDKey && f.Kind != KindError:
+			rec.SpanID = otelID(f, 8)
+			if rec.SpanID != "" {
+				continue
+			}
+		case f.Kind == KindError:
+			err, _ := f.Value.(error)
+			if err == nil {
+				continue
+			}
+			rec.Attributes = append(rec.Attributes,
+				OTelKeyValue{Key: "exception.message", Value: OTelString(err.Error())},
+				OTelKeyValue{Key: "exception.type", Value: OTelString(fmt.Sprintf("%T", err))},
+			)
+			continue
+		}
+		rec.Attributes = append(rec.Attributes, OTelKeyValue{Key: f.Key, Value: otelFieldValue(f)})
+	}
+
+	return rec
+}
+
+// otelID returns the hex-encoded ID contained in the field,
+// or an empty string if the field doesn't contain a valid ID
+// of the given size
+func otelID(f Field, size int) string {
+	switch f.Kind {
+	case KindBytes:
+		if b := f.Value.([]byte); len(b) == size {
+			return hex.EncodeToString(b)
+		}
+	case KindString:
+		s := f.Value.(string)
+		if _, err := hex.DecodeString(s); err == nil && len(s) == size*2 {
+			return strings.ToLower(s)
+		}
+	}
+	return ""
+}
+
+// otelFieldValue converts the value of a field to an OTelAnyValue
+func otelFieldValue(f Field) OTelAnyValue {
+	switch f.Kind {
+	case KindInt:
+		return OTelInt(f.Value.(int64))
+	case KindUint:
+		u := f.Value.(uint64)
+		if u > math.MaxInt64 {
+			return OTelString(strconv.FormatUint(u, 10))
+		}
+		return OTelInt(int64(u))
+	case KindFloat32:
+		return OTelDouble(float64(f.Value.(float32)))
+	case KindFloat64:
+		return OTelDouble(f.Value.(float64))
+	case KindBool:
+		b := f.Value.(bool)
+		return OTelAnyValue{BoolValue: &b}
+	case KindString:
+		return OTelString(f.Value.(string))
+	case KindBytes:
+		return OTelAnyValue{BytesValue: f.Value.([]byte)}
+	case KindTime:
+		return OTelString(f.Value.(time.Time).Format(time.RFC3339Nano))
+	case KindError:
+		return OTelString(f.Value.(error).Error())
+	default:
+		// Marshal and unmarshal the value so that
+		// structs and maps become generic values
+		data, err := json.Marshal(f.Value)
+		if err != nil {
+			panic(err)
+		}
+		var v any
+		json.Unmarshal(data, &v)
+		return otelAny(v)
+	}
+}
+
+// otelAny converts a generic value decoded
+// from JSON to an OTelAnyValue
+func otelAny(v any) OTelAnyValue {
+	switch v := v.(type) {
+	case string:
+		return OTelString(v)
+	case bool:
+		return OTelAnyValue{BoolValue: &v}
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return OTelInt(int64(v))
+		}
+		return OTelDouble(v)
+	case []any:
+		arr := &OTelArrayValue{Values: make([]OTelAnyValue, len(v))}
+		for i, val := range v {
+			arr.Values[i] = otelAny(val)
+		}
+		return OTelAnyValue{ArrayValue: arr}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		kvl := &OTelKeyValueList{Values: make([]OTelKeyValue, len(keys))}
+		for i, key := range keys {
+			kvl.Values[i] = OTelKeyValue{Key: key, Value: otelAny(v[key])}
+		}
+		return OTelAnyValue{KvlistValue: kvl}
+	default:
+		return OTelAnyValue{}
+	}
+}
+
+// OTelLogRecord is a log record in the OpenTelemetry
+// logs data model, using OTLP/JSON field names
+type OTelLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 OTelAnyValue   `json:"body"`
+	Attributes           []OTelKeyValue `json:"attributes,omitempty"`
+	TraceID              string         `json:"traceId,omitempty"`
+	SpanID               string         `json:"spanId,omitempty"`
+}
+
+// OTelKeyValue is a key-value pair used for attributes
+type OTelKeyValue struct {
+	Key   string       `json:"key"`
+	Value OTelAnyValue `json:"value"`
+}
+
+// OTelAnyValue is a typed value. Only one field should be set.
+type OTelAnyValue struct {
+	StringValue *string           `json:"stringValue,omitempty"`
+	BoolValue   *bool             `json:"boolValue,omitempty"`
+	IntValue    *string           `json:"intValue,omitempty"`
+	DoubleValue *float64          `json:"doubleValue,omitempty"`
+	BytesValue  []byte            `json:"bytesValue,omitempty"`
+	ArrayValue  *OTelArrayValue   `json:"arrayValue,omitempty"`
+	KvlistValue *OTelKeyValueList `json:"kvlistValue,omitempty"`
+}
+
+// OTelArrayValue is a list of values
+type OTelArrayValue struct {
+	Values []OTelAnyValue `json:"values"`
+}
+
+// OTelKeyValueList is a list of key-value pairs
+type OTelKeyValueList struct {
+	Values []OTelKeyValue `json:"values"`
+}
+
+// OTelString creates a new OTelAnyValue containing a string
+func OTelString(s string) OTelAnyValue {
+	return OTelAnyValue{StringValue: &s}
+}
+
+// OTelInt creates a new OTelAnyValue containing an integer.
+// OTLP/JSON encodes 64-bit integers as strings.
+func OTelInt(i int64) OTelAnyValue {
+	s := strconv.FormatInt(i, 10)
+	return OTelAnyValue{IntValue: &s}
+}
+
+// OTelDouble creates a new OTelAnyValue containing a float
+func OTelDouble(f float64) OTelAnyValue {
+	return OTelAnyValue{DoubleValue: &f}
+}
@@ -0,0 +1,181 @@
+package logger_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"go.elara.ws/logger"
+)
+
+// otlpRecords decodes the log records exported to hc
+func otlpRecords(t *testing.T, hc *httpCollector) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, body := range hc.requestBodies() {
+		var req struct {
+			ResourceLogs []struct {
+				ScopeLogs []struct {
+					LogRecords []map[string]any `json:"logRecords"`
+				} `json:"scopeLogs"`
+			} `json:"resourceLogs"`
+		}
+		if err := json.Unmarshal([]byte(body), &req); err != nil {
+			t.Fatal(err)
+		}
+		for _, rl := range req.ResourceLogs {
+			for _, sl := range rl.ScopeLogs {
+				records = append(records, sl.LogRecords...)
+			}
+		}
+	}
+	return records
+}
+
+// newOTLPTest creates an OTLPExporter that exports to hc
+func newOTLPTest(t *testing.T, hc *httpCollector) *logger.OTLPExporter {
+	t.Helper()
+	exp := logger.NewOTLPExporter(serveCollector(t, hc)+"/v1/logs", time.Hour)
+	startBatchingTest(t, exp, exp.Batching)
+	return exp
+}
+
+func TestOTel(t *testing.T) {
+	t.Run("record", func(t *testing.T) {
+		hc := &httpCollector{}
+		exp := newOTLPTest(t, hc)
+		otellog := logger.NewOTel(exp)
+
+		otellog.Warn("Test").
+			Int("n", 1234).
+			Bool("ok", true).
+			Float64("pi", 3.14).
+			Str("trace_id", "0102030405060708090a0b0c0d0e0f10").
+			Bytes("span_id", []byte{1, 2, 3, 4, 5, 6, 7, 8}).
+			Err(errors.New("err")).
+			Send()
+
+		if err := exp.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		records := otlpRecords(t, hc)
+		if len(records) != 1 {
+			t.Fatalf("expected 1 record, got %d", len(records))
+		}
+		rec := records[0]
+
+		if got, want := rec["severityNumber"], float64(13); got != want {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+		if got, want := rec["severityText"], "WARN"; got != want {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+		if got, want := rec["body"].(map[string]any)["stringValue"], "Test"; got != want {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+		if got, want := rec["traceId"], "0102030405060708090a0b0c0d0e0f10"; got != want {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+		if got, want := rec["spanId"], "0102030405060708"; got != want {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+
+		attrs := map[string]map[string]any{}
+		for _, attr := range rec["attributes"].([]any) {
+			kv := attr.(map[string]any)
+			attrs[kv["key"].(string)] = kv["value"].(map[string]any)
+		}
+		if got, want := attrs["n"]["intValue"], "1234"; got != want {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+		if got, want := attrs["ok"]["boolValue"], true; got != want {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+		if got, want := attrs["pi"]["doubleValue"], 3.14; got != want {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+		if got, want := attrs["exception.message"]["stringValue"], "err"; got != want {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+		if _, ok := attrs["trace_id"]; ok {
+			t.Error("expected trace_id to be removed from attributes")
+		}
+	})
+
+	t.Run("not-writer", func(t *testing.T) {
+		exp := newOTLPTest(t, &httpCollector{})
+		if _, ok := any(exp).(io.Writer); ok {
+			t.Error("OTLPExporter shouldn't implement io.Writer")
+		}
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		hc := &httpCollector{}
+		exp := newOTLPTest(t, hc)
+		exp.BatchSize = 3
+		otellog := logger.NewOTel(exp)
+
+		for i := 0; i < 3; i++ {
+			otellog.Info("Test").Int("i", i).Send()
+		}
+
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			n := len(otlpRecords(t, hc))
+			if n == 3 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("expected 3 records, got %d", n)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		hc := &httpCollector{failures: 2}
+		exp := newOTLPTest(t, hc)
+		otellog := logger.NewOTel(exp)
+		otellog.Info("Test").Send()
+
+		if err := exp.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := hc.requests, 3; got != want {
+			t.Errorf("got: %d, want: %d", got, want)
+		}
+		if got, want := len(otlpRecords(t, hc)), 1; got != want {
+			t.Errorf("got: %d, want: %d", got, want)
+		}
+	})
+
+	t.Run("give-up", func(t *testing.T) {
+		hc := &httpCollector{failures: 100}
+		exp := newOTLPTest(t, hc)
+		exp.MaxRetries = 2
+		otellog := logger.NewOTel(exp)
+		otellog.Info("Test").Send()
+
+		if err := exp.Flush(); err == nil {
+			t.Error("expected error")
+		}
+		if got, want := hc.requests, 3; got != want {
+			t.Errorf("got: %d, want: %d", got, want)
+		}
+	})
+	t.Run("closed", func(t *testing.T) {
+		exp := newOTLPTest(t, &httpCollector{})
+		otellog := logger.NewOTel(exp)
+		assertClosedError(t, exp, exp.Batching, func() { otellog.Info("Test").Send() })
+	})
+
+	t.Run("zero-interval", func(t *testing.T) {
+		exp := logger.NewOTLPExporter("http://localhost:0/v1/logs", 0)
+		if err := exp.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// OTLPExporter batches OpenTelemetry log records and exports
+// them to a collector using OTLP/HTTP. It's built on an HTTPWriter
+// whose body format wraps each batch in a logs request with the
+// exporter's resource and scope, and shares its endpoint and
+// batching settings, but it isn't an io.Writer, since records
+// have to be added using Export.
+//
+// Only the JSON encoding of OTLP/HTTP is supported. Collectors
+// that only accept protobuf, such as some managed services,
+// can't be used with this exporter.
+type OTLPExporter struct {
+	*HTTPEndpoint
+	*Batching
+
+	// Resource contains the attributes of the resource
+	// producing the logs, such as service.name
+	Resource []OTelKeyValue
+	// ScopeName is the name of the instrumentation scope
+	ScopeName string
+
+	hw *HTTPWriter
+}
+
+// NewOTLPExporter creates a new OTLPExporter that sends records
+// to the given endpoint, such as http://localhost:4318/v1/logs,
+// and starts a goroutine that exports them in the background
+// every flush interval.
+func NewOTLPExporter(endpoint string, interval time.Duration) *OTLPExporter {
+	oe := &OTLPExporter{ScopeName: "go.elara.ws/logger"}
+	oe.hw = NewHTTPWriter(endpoint, interval)
+	oe.hw.Format = HTTPBodyFormat{ContentType: "application/json", Encode: oe.encode}
+	oe.hw.Compress = false
+	oe.hw.BatchSize = 512
+	oe.hw.MaxBackoff = 5 * time.Second
+	oe.HTTPEndpoint = &oe.hw.HTTPEndpoint
+	oe.Batching = &oe.hw.Batching
+	return oe
+}
+
+// Export adds a record to the current batch
+func (oe *OTLPExporter) Export(rec OTelLogRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return oe.enqueue(data)
+}
+
+// encode writes a logs request containing the
+// given batch of encoded records to buf
+func (oe *OTLPExporter) encode(buf *bytes.Buffer, records [][]byte) error {
+	raw := make([]json.RawMessage, len(records))
+	for i, rec := range records {
+		raw[i] = rec
+	}
+
+	return json.NewEncoder(buf).Encode(otlpRequest{
+		ResourceLogs: []otlpResourceLogs{{
+			Resource: otlpResource{Attributes: oe.Resource},
+			ScopeLogs: []otlpScopeLogs{{
+				Scope:      otlpScope{Name: oe.ScopeName},
+				LogRecords: raw,
+			}},
+		}},
+	})
+}
+
+// otlpRequest is the body of an OTLP/HTTP logs request
+type otlpRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpResource struct {
+	Attributes []OTelKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope         `json:"scope"`
+	LogRecords []json.RawMessage `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
@@ -0,0 +1,57 @@
+package logger
+
+import "time"
+
+// PanicEvent is the value loggers panic with when a panic
+// event is sent, so that code recovering from the panic
+// can find out what was logged.
+type PanicEvent struct {
+	Level   LogLevel
+	Message string
+	Fields  []Field
+	// Err is the error added to the event
+	// using Err, if there is one
+	Err error
+}
+
+// NewPanicEvent creates and returns a new
+// PanicEvent containing the given event
+func NewPanicEvent(e *Event) *PanicEvent {
+	pe := &PanicEvent{
+		Level:   e.Level,
+		Message: e.Message,
+		Fields:  e.Fields,
+	}
+	for _, f := range e.Fields {
+		if err, ok := f.Value.(error); ok && f.Kind == KindError && err != nil {
+			pe.Err = err
+		}
+	}
+	return pe
+}
+
+// Error returns the event's message, followed
+// by its error if it has one
+func (pe *PanicEvent) Error() string {
+	if pe.Err != nil {
+		return pe.Message + ": " + pe.Err.Error()
+	}
+	return pe.Message
+}
+
+// Unwrap returns the event's error
+func (pe *PanicEvent) Unwrap() error {
+	return pe.Err
+}
+
+// capturePanic returns a builder that collects the fields of a
+// panic event and adds them to lb when it's sent. Before lb is
+// sent, a PanicEvent containing the fields is stored in pe, so
+// that builders writing directly to their output can panic
+// with it.
+func capturePanic(lb LogBuilder, msg string, now func() time.Time, pe **PanicEvent) LogBuilder {
+	return newEventLogBuilder(LogLevelPanic, msg, now, func(e *Event) {
+		*pe = NewPanicEvent(e)
+		e.Apply(lb).Send()
+	})
+}
@@ -0,0 +1,78 @@
+package logger_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"go.elara.ws/logger"
+)
+
+// recoverPanic calls fn and returns the value it panicked with
+func recoverPanic(fn func()) (v any) {
+	defer func() { v = recover() }()
+	fn()
+	return nil
+}
+
+func TestPanicEvent(t *testing.T) {
+	errTest := errors.New("test error")
+
+	for name, newLogger := range map[string]func(*bytes.Buffer) logger.Logger{
+		"json":   func(buf *bytes.Buffer) logger.Logger { return logger.NewJSON(buf) },
+		"pretty": func(buf *bytes.Buffer) logger.Logger { return logger.NewPretty(buf) },
+		"cli":    func(buf *bytes.Buffer) logger.Logger { return logger.NewCLI(buf) },
+		"gelf":   func(buf *bytes.Buffer) logger.Logger { return logger.NewGELF(buf) },
+		"ecs":    func(buf *bytes.Buffer) logger.Logger { return logger.NewECS(buf) },
+		"multi": func(buf *bytes.Buffer) logger.Logger {
+			return logger.NewMulti(logger.NewJSON(buf), logger.NewCLI(&bytes.Buffer{}))
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			l := newLogger(buf)
+
+			v := recoverPanic(func() {
+				l.Panicf("Test %d", 1).Str("a", "b").Err(errTest).Send()
+			})
+
+			pe, ok := v.(*logger.PanicEvent)
+			if !ok {
+				t.Fatalf("expected *logger.PanicEvent, got: %#v", v)
+			}
+			if got, want := pe.Error(), "Test 1: test error"; got != want {
+				t.Errorf("got: %s, want: %s", got, want)
+			}
+			if !errors.Is(pe, errTest) {
+				t.Error("expected panic event to wrap error")
+			}
+			if got, want := len(pe.Fields), 2; got != want {
+				t.Fatalf("got: %d fields, want: %d", got, want)
+			}
+			if got, want := pe.Fields[0].Key, "a"; got != want {
+				t.Errorf("got: %s, want: %s", got, want)
+			}
+			if buf.Len() == 0 {
+				t.Error("expected event to be written before panicking")
+			}
+		})
+	}
+
+	t.Run("output", func(t *testing.T) {
+		// The output of panic events must be the same
+		// as if the fields were written directly
+		panicBuf, noPanicBuf := &bytes.Buffer{}, &bytes.Buffer{}
+		pl := logger.NewJSON(panicBuf)
+		npl := logger.NewJSON(noPanicBuf)
+		npl.NoPanic()
+
+		recoverPanic(func() {
+			pl.Panic("Test").Int("n", 1).Bytes("b", []byte("x")).Any("any", []int{1}).Err(errTest).Send()
+		})
+		npl.Panic("Test").Int("n", 1).Bytes("b", []byte("x")).Any("any", []int{1}).Err(errTest).Send()
+
+		if got, want := panicBuf.String(), noPanicBuf.String(); got != want {
+			t.Errorf("got: %s, want: %s", got, want)
+		}
+	})
+}
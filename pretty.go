@@ -37,6 +37,10 @@ type PrettyLogger struct {
 	FatalColor color.Color
 	PanicColor color.Color
 
+	ErrorHandling
+	Environment
+	Hooks
+
 	noPanic bool
 	noExit  bool
 }
@@ -86,6 +90,13 @@ func (pl *PrettyLogger) SetLevel(l LogLevel) {
 	pl.Level = l
 }
 
+// Enabled reports whether events with the given level are logged.
+// If any hooks have been added, every level is enabled, since the
+// hooks may change the level of events.
+func (pl *PrettyLogger) Enabled(lvl LogLevel) bool {
+	return pl.Out != io.Discard && (lvl >= pl.Level || pl.hooked())
+}
+
 // Debug creates a new debug event with the given message
 func (pl *PrettyLogger) Debug(msg string) LogBuilder {
 	return newPrettyLogBuilder(pl, msg, LogLevelDebug)
@@ -160,18 +171,49 @@ type PrettyLogBuilder struct {
 	l   *PrettyLogger
 	lvl LogLevel
 	out writer
+	pe  *PanicEvent
+	now func() time.Time
 }
 
 func newPrettyLogBuilder(pl *PrettyLogger, msg string, lvl LogLevel) LogBuilder {
-	if pl.Out == io.Discard || lvl < pl.Level {
+	return newPrettyLogBuilderClock(pl, msg, lvl, pl.Now)
+}
+
+// newPrettyLogBuilderClock creates a new builder for an
+// event whose time is returned by now
+func newPrettyLogBuilderClock(pl *PrettyLogger, msg string, lvl LogLevel, now func() time.Time) LogBuilder {
+	if !pl.Enabled(lvl) {
 		return NopLogBuilder{}
 	}
+	if pl.hooked() {
+		return pl.withHooks(lvl, msg, now, func(lvl LogLevel, msg string) LogBuilder {
+			// The level is checked after the hooks have run,
+			// since they may have changed it
+			if lvl < pl.Level {
+				return NopLogBuilder{}
+			}
+			return startPrettyLogBuilder(pl, msg, lvl, now)
+		})
+	}
+	return startPrettyLogBuilder(pl, msg, lvl, now)
+}
+
+// LogEvent logs a collected event, keeping its time
+func (pl *PrettyLogger) LogEvent(e *Event) {
+	now := func() time.Time { return e.Time }
+	e.Apply(newPrettyLogBuilderClock(pl, e.Message, e.Level, now)).Send()
+}
+
+// startPrettyLogBuilder creates a new builder and
+// writes the beginning of the event to it
+func startPrettyLogBuilder(pl *PrettyLogger, msg string, lvl LogLevel, now func() time.Time) LogBuilder {
 	lb := &PrettyLogBuilder{
 		l:   pl,
 		out: writer{&bytes.Buffer{}, pl.Out},
 		lvl: lvl,
+		now: now,
 	}
-	lb.writeColor(lb.l.TimeColor, time.Now().Format(lb.l.TimeFormat))
+	lb.writeColor(lb.l.TimeColor, now().Format(lb.l.TimeFormat))
 	lb.out.WriteByte(' ')
 
 	switch lvl {
@@ -191,6 +233,9 @@ func newPrettyLogBuilder(pl *PrettyLogger, msg string, lvl LogLevel) LogBuilder
 	lb.out.WriteByte(' ')
 
 	lb.writeColor(lb.l.MsgColor, msg)
+	if lvl == LogLevelPanic && !pl.noPanic {
+		return capturePanic(lb, msg, now, &lb.pe)
+	}
 	return lb
 }
 
@@ -286,7 +331,7 @@ func (plb *PrettyLogBuilder) Bytes(key string, b []byte) LogBuilder {
 // Timestamp adds the time formatted as RFC3339Nano
 // as a field to the output
 func (plb *PrettyLogBuilder) Timestamp() LogBuilder {
-	return plb.Str("timestamp", time.Now().Format(time.RFC3339Nano))
+	return plb.Str("timestamp", plb.now().Format(time.RFC3339Nano))
 }
 
 // Bool adds a bool as a field to the output
@@ -335,11 +380,11 @@ func (plb *PrettyLogBuilder) Err(err error) LogBuilder {
 // After calling send, do not use the event again.
 func (plb *PrettyLogBuilder) Send() {
 	plb.out.WriteByte('\n')
-	plb.out.Flush()
+	plb.l.flush(plb.out)
 	if plb.lvl == LogLevelFatal && !plb.l.noExit {
-		os.Exit(1)
+		plb.l.exit()
 	} else if plb.lvl == LogLevelPanic && !plb.l.noPanic {
-		panic("")
+		plb.l.doPanic(plb.pe)
 	}
 }
 
@@ -0,0 +1,390 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ Logger = (*Redactor)(nil)
+
+// RedactMode controls how sensitive values are masked
+type RedactMode uint8
+
+// Redaction modes
+const (
+	// RedactReplace replaces values with the Replacement string
+	RedactReplace RedactMode = iota
+	// RedactHash replaces values with a truncated HMAC-SHA256
+	// of the value keyed with the Redactor's HashKey, so that
+	// they can still be correlated between events
+	RedactHash
+	// RedactPartial masks all but the last four characters
+	RedactPartial
+)
+
+// Patterns matching common sensitive values
+var (
+	RedactJWT        = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)
+	RedactBearer     = regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._~+/=-]+`)
+	RedactCardNumber = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	RedactEmail      = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+)
+
+// DefaultRedactKeys are the key patterns used by NewRedactor
+var DefaultRedactKeys = []string{
+	"*password*",
+	"*passwd*",
+	"*secret*",
+	"*token*",
+	"authorization",
+	"cookie",
+	"*api_key*",
+	"*apikey*",
+}
+
+// DefaultRedactPatterns are the value patterns used by NewRedactor
+var DefaultRedactPatterns = []*regexp.Regexp{
+	RedactJWT,
+	RedactBearer,
+	RedactCardNumber,
+	RedactEmail,
+}
+
+// Redactor implements the Logger interface by masking sensitive
+// values before passing events to an underlying logger, so it
+// works the same way with any logger.
+//
+// Values are masked if their key matches one of Keys, and any
+// parts of string values that match one of Patterns are masked
+// as well. Values added using Any are searched recursively, using
+// their JSON representation.
+type Redactor struct {
+	Logger Logger
+
+	// Keys are glob patterns matched case-insensitively
+	// against field keys. A * matches any sequence of
+	// characters, including dots and slashes, so that
+	// *token* matches keys such as auth.token and
+	// headers/x-token, and ? matches a single character.
+	Keys []string
+	// Patterns are matched against string values
+	// and messages
+	Patterns []*regexp.Regexp
+
+	// Mode controls how values are masked
+	Mode RedactMode
+	// Replacement is used by RedactReplace
+	Replacement string
+	// HashKey is the key used by RedactHash. Hashes only
+	// correlate between events hashed with the same key. If
+	// it's empty, a random key is generated once per process,
+	// so hashes can't be compared between processes. The key
+	// has to be kept secret, since values such as card numbers
+	// and emails can be found by brute force with it.
+	HashKey []byte
+}
+
+var (
+	redactKeyOnce sync.Once
+	redactKey     []byte
+)
+
+// defaultRedactKey returns the random key used by
+// RedactHash when a Redactor has no HashKey
+func defaultRedactKey() []byte {
+	redactKeyOnce.Do(func() {
+		redactKey = make([]byte, 32)
+		if _, err := rand.Read(redactKey); err != nil {
+			panic(err)
+		}
+	})
+	return redactKey
+}
+
+// NewRedactor creates and returns a new Redactor wrapping l
+// that uses the default keys and patterns
+func NewRedactor(l Logger) *Redactor {
+	return &Redactor{
+		Logger:      l,
+		Keys:        DefaultRedactKeys,
+		Patterns:    DefaultRedactPatterns,
+		Replacement: "[REDACTED]",
+	}
+}
+
+// NoPanic prevents the logger from panicking on panic events
+func (r *Redactor) NoPanic() {
+	r.Logger.NoPanic()
+}
+
+// NoExit prevents the logger from exiting on fatal events
+func (r *Redactor) NoExit() {
+	r.Logger.NoExit()
+}
+
+// Now returns the current time using
+// the clock of the underlying logger
+func (r *Redactor) Now() time.Time {
+	return clockOf(r.Logger)()
+}
+
+// Enabled reports whether events with the given level are logged
+func (r *Redactor) Enabled(lvl LogLevel) bool {
+	return enabled(r.Logger, lvl)
+}
+
+// SetLevel sets the log level of the logger
+func (r *Redactor) SetLevel(l LogLevel) {
+	r.Logger.SetLevel(l)
+}
+
+// Debug creates a new debug event with the given message
+func (r *Redactor) Debug(msg string) LogBuilder {
+	return newRedactLogBuilder(r, msg, LogLevelDebug)
+}
+
+// Debugf creates a new debug event with the formatted message
+func (r *Redactor) Debugf(format string, v ...any) LogBuilder {
+	return newRedactLogBuilder(r, fmt.Sprintf(format, v...), LogLevelDebug)
+}
+
+// Info creates a new info event with the given message
+func (r *Redactor) Info(msg string) LogBuilder {
+	return newRedactLogBuilder(r, msg, LogLevelInfo)
+}
+
+// Infof creates a new info event with the formatted message
+func (r *Redactor) Infof(format string, v ...any) LogBuilder {
+	return newRedactLogBuilder(r, fmt.Sprintf(format, v...), LogLevelInfo)
+}
+
+// Warn creates a new warn event with the given message
+func (r *Redactor) Warn(msg string) LogBuilder {
+	return newRedactLogBuilder(r, msg, LogLevelWarn)
+}
+
+// Warnf creates a new warn event with the formatted message
+func (r *Redactor) Warnf(format string, v ...any) LogBuilder {
+	return newRedactLogBuilder(r, fmt.Sprintf(format, v...), LogLevelWarn)
+}
+
+// Error creates a new error event with the given message
+func (r *Redactor) Error(msg string) LogBuilder {
+	return newRedactLogBuilder(r, msg, LogLevelError)
+}
+
+// Errorf creates a new error event with the formatted message
+func (r *Redactor) Errorf(format string, v ...any) LogBuilder {
+	return newRedactLogBuilder(r, fmt.Sprintf(format, v...), LogLevelError)
+}
+
+// Fatal creates a new fatal event with the given message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (r *Redactor) Fatal(msg string) LogBuilder {
+	return newRedactLogBuilder(r, msg, LogLevelFatal)
+}
+
+// Fatalf creates a new fatal event with the formatted message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (r *Redactor) Fatalf(format string, v ...any) LogBuilder {
+	return newRedactLogBuilder(r, fmt.Sprintf(format, v...), LogLevelFatal)
+}
+
+// Panic creates a new panic event with the given message
+//
+// When sent, panic events will cause a panic
+func (r *Redactor) Panic(msg string) LogBuilder {
+	return newRedactLogBuilder(r, msg, LogLevelPanic)
+}
+
+// Panicf creates a new panic event with the formatted message
+//
+// When sent, panic events will cause a panic
+func (r *Redactor) Panicf(format string, v ...any) LogBuilder {
+	return newRedactLogBuilder(r, fmt.Sprintf(format, v...), LogLevelPanic)
+}
+
+func newRedactLogBuilder(r *Redactor, msg string, lvl LogLevel) LogBuilder {
+	if !r.Enabled(lvl) {
+		return NopLogBuilder{}
+	}
+	return newEventLogBuilder(lvl, msg, r.Now, r.send)
+}
+
+// send redacts the event and sends it to the underlying logger
+func (r *Redactor) send(e *Event) {
+	r.Redact(e)
+	e.Replay(r.Logger)
+}
+
+// Redact masks the sensitive values in e
+func (r *Redactor) Redact(e *Event) {
+	e.Message = r.redactString(e.Message)
+	for i, f := range e.Fields {
+		e.Fields[i] = r.redactField(f)
+	}
+}
+
+// redactField returns f with its sensitive values masked
+func (r *Redactor) redactField(f Field) Field {
+	if f.Kind != KindError && r.matchKey(f.Key) {
+		return Field{Key: f.Key, Kind: KindString, Value: r.mask(f.ValueString())}
+	}
+
+	switch f.Kind {
+	case KindString:
+		f.Value = r.redactString(f.Value.(string))
+	case KindBytes:
+		b, _ := f.Value.([]byte)
+		if s := r.redactString(string(b)); s != string(b) {
+			f.Value = []byte(s)
+		}
+	case KindError:
+		err, _ := f.Value.(error)
+		if err == nil {
+			break
+		}
+		if msg := r.redactString(err.Error()); msg != err.Error() {
+			f.Value = errors.New(msg)
+		}
+	case KindAny:
+		f.Value = r.redactAny(f.Value)
+	}
+	return f
+}
+
+// matchKey reports whether key matches any of the key patterns
+func (r *Redactor) matchKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range r.Keys {
+		if matchGlob(strings.ToLower(pattern), key) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob reports whether s matches pattern, where * matches any
+// sequence of characters and ? matches a single character. Unlike
+// path.Match, * isn't limited to a single path element.
+func matchGlob(pattern, s string) bool {
+	p, str := []rune(pattern), []rune(s)
+	pi, si := 0, 0
+	// Position of the last * in the pattern, and the position
+	// in s it's currently matched up to, for backtracking
+	star, match := -1, 0
+	for si < len(str) {
+		switch {
+		case pi < len(p) && p[pi] == '*':
+			star, match = pi, si
+			pi++
+		case pi < len(p) && (p[pi] == '?' || p[pi] == str[si]):
+			pi++
+			si++
+		case star >= 0:
+			match++
+			pi, si = star+1, match
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
+
+// redactString masks the parts of s that match any of the patterns
+func (r *Redactor) redactString(s string) string {
+	for _, re := range r.Patterns {
+		s = re.ReplaceAllStringFunc(s, r.mask)
+	}
+	return s
+}
+
+// redactAny masks sensitive values in the JSON representation
+// of val. If nothing is masked, val is returned unchanged.
+func (r *Redactor) redactAny(val any) any {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return val
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return val
+	}
+
+	if out, changed := r.redactJSON(generic); changed {
+		return out
+	}
+	return val
+}
+
+// redactJSON recursively masks sensitive values in a value decoded
+// by encoding/json and reports whether anything was masked
+func (r *Redactor) redactJSON(val any) (any, bool) {
+	switch val := val.(type) {
+	case string:
+		out := r.redactString(val)
+		return out, out != val
+	case []any:
+		changed := false
+		for i, elem := range val {
+			var c bool
+			val[i], c = r.redactJSON(elem)
+			changed = changed || c
+		}
+		return val, changed
+	case map[string]any:
+		changed := false
+		for k, v := range val {
+			if r.matchKey(k) {
+				val[k] = r.mask(fmt.Sprint(v))
+				changed = true
+				continue
+			}
+			var c bool
+			val[k], c = r.redactJSON(v)
+			changed = changed || c
+		}
+		return val, changed
+	default:
+		return val, false
+	}
+}
+
+// mask masks a sensitive value according to the mode
+func (r *Redactor) mask(s string) string {
+	switch r.Mode {
+	case RedactHash:
+		key := r.HashKey
+		if len(key) == 0 {
+			key = defaultRedactKey()
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(s))
+		return "hmac:" + hex.EncodeToString(mac.Sum(nil)[:8])
+	case RedactPartial:
+		runes := []rune(s)
+		if len(runes) <= 4 {
+			return strings.Repeat("*", len(runes))
+		}
+		return strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-4:])
+	default:
+		return r.Replacement
+	}
+}
@@ -0,0 +1,308 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ io.WriteCloser = (*RotatingFile)(nil)
+
+// backupTimeFormat is the format of the timestamp
+// added to the names of rotated files
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotationInterval represents how often
+// a RotatingFile is rotated
+type RotationInterval uint8
+
+// Rotation intervals
+const (
+	RotateNever RotationInterval = iota
+	RotateHourly
+	RotateDaily
+)
+
+// next returns the time of the first rotation after t
+func (ri RotationInterval) next(t time.Time) time.Time {
+	switch ri {
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Time{}
+	}
+}
+
+// RotatingFile is an io.Writer that writes to a file and rotates
+// it when it gets too large or when the rotation interval elapses.
+// Rotated files are renamed by adding a timestamp to their name,
+// so app.log becomes app-2006-01-02T15-04-05.000.log.
+//
+// RotatingFile is safe for concurrent use, so it can be shared
+// between several loggers.
+type RotatingFile struct {
+	// Filename is the path of the file to write to
+	Filename string
+
+	// MaxSize is the maximum size of the file in bytes
+	// before it's rotated. Zero means no limit.
+	MaxSize int64
+
+	// Interval is how often the file is rotated
+	// regardless of its size
+	Interval RotationInterval
+
+	// MaxBackups is the maximum amount of rotated
+	// files to keep. Zero means no limit.
+	MaxBackups int
+
+	// MaxAge is the maximum age of rotated files
+	// to keep. Zero means no limit.
+	MaxAge time.Duration
+
+	// Compress enables gzip compression of rotated files
+	Compress bool
+
+	mu           sync.Mutex
+	file         *os.File
+	size         int64
+	nextRotation time.Time
+
+	millMu sync.Mutex
+	millWG sync.WaitGroup
+}
+
+// NewRotatingFile creates and returns a new RotatingFile
+// that writes to the given file. The file is opened
+// when it's first written to.
+func NewRotatingFile(filename string) *RotatingFile {
+	return &RotatingFile{Filename: filename}
+}
+
+// Write writes p to the file, rotating it first if required
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.file == nil {
+		if err := rf.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	sizeExceeded := rf.MaxSize > 0 && rf.size > 0 && rf.size+int64(len(p)) > rf.MaxSize
+	intervalElapsed := !rf.nextRotation.IsZero() && !time.Now().Before(rf.nextRotation)
+	if sizeExceeded || intervalElapsed {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the current file, renames it,
+// and opens a new one
+func (rf *RotatingFile) Rotate() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	return rf.rotate()
+}
+
+// Reopen closes and reopens the file without rotating it.
+// This is meant to be called on SIGHUP, after the file
+// has been moved by an external tool such as logrotate.
+func (rf *RotatingFile) Reopen() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.file != nil {
+		rf.file.Close()
+		rf.file = nil
+	}
+	return rf.open()
+}
+
+// Close closes the file and waits for any
+// compression or cleanup to finish
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	var err error
+	if rf.file != nil {
+		err = rf.file.Close()
+		rf.file = nil
+	}
+	rf.mu.Unlock()
+
+	rf.millWG.Wait()
+	return err
+}
+
+// open opens the file for appending, creating it if needed
+func (rf *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(rf.Filename), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(rf.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	rf.file = f
+	rf.size = fi.Size()
+	rf.nextRotation = rf.Interval.next(time.Now())
+	return nil
+}
+
+// rotate renames the current file and opens a new one.
+// Compression and removal of old files is done
+// in the background.
+func (rf *RotatingFile) rotate() error {
+	if rf.file != nil {
+		if err := rf.file.Close(); err != nil {
+			return err
+		}
+		rf.file = nil
+	}
+
+	// Make sure an existing backup isn't overwritten if
+	// the file is rotated more than once per millisecond
+	t := time.Now()
+	backup := rf.backupName(t)
+	for fileExists(backup) || fileExists(backup+".gz") {
+		t = t.Add(time.Millisecond)
+		backup = rf.backupName(t)
+	}
+	if err := os.Rename(rf.Filename, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := rf.open(); err != nil {
+		return err
+	}
+
+	rf.millWG.Add(1)
+	go rf.mill(backup)
+	return nil
+}
+
+// backupName returns the name of a backup file rotated at t
+func (rf *RotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(rf.Filename)
+	prefix := strings.TrimSuffix(rf.Filename, ext)
+	return prefix + "-" + t.Format(backupTimeFormat) + ext
+}
+
+// mill compresses the given backup, if enabled,
+// and removes backups exceeding the configured limits
+func (rf *RotatingFile) mill(backup string) {
+	defer rf.millWG.Done()
+	rf.millMu.Lock()
+	defer rf.millMu.Unlock()
+
+	if rf.Compress {
+		compressFile(backup)
+	}
+
+	if rf.MaxBackups <= 0 && rf.MaxAge <= 0 {
+		return
+	}
+
+	backups := rf.backups()
+	cutoff := time.Now().Add(-rf.MaxAge)
+	for i, b := range backups {
+		if (rf.MaxBackups > 0 && i >= rf.MaxBackups) || (rf.MaxAge > 0 && b.time.Before(cutoff)) {
+			os.Remove(b.path)
+		}
+	}
+}
+
+// fileExists reports whether a file exists at path
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// backupFile is a rotated file
+type backupFile struct {
+	path string
+	time time.Time
+}
+
+// backups returns all the backups of the file,
+// sorted from newest to oldest
+func (rf *RotatingFile) backups() []backupFile {
+	dir := filepath.Dir(rf.Filename)
+	ext := filepath.Ext(rf.Filename)
+	prefix := strings.TrimSuffix(filepath.Base(rf.Filename), ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var out []backupFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		ts := strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ext)
+		ts = strings.TrimPrefix(ts, prefix)
+		t, err := time.ParseInLocation(backupTimeFormat, ts, time.Local)
+		if err != nil {
+			continue
+		}
+		out = append(out, backupFile{filepath.Join(dir, name), t})
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].time.After(out[j].time)
+	})
+	return out
+}
+
+// compressFile compresses the file at path using gzip,
+// replacing it with path + ".gz"
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
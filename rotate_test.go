@@ -0,0 +1,145 @@
+package logger_test
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.elara.ws/logger"
+)
+
+func TestRotatingFile(t *testing.T) {
+	t.Run("size", func(t *testing.T) {
+		dir := t.TempDir()
+		rf := logger.NewRotatingFile(filepath.Join(dir, "app.log"))
+		rf.MaxSize = 100
+		rf.MaxBackups = 2
+
+		jsonlog := logger.NewJSON(rf)
+		for i := 0; i < 20; i++ {
+			jsonlog.Info("Test").Int("i", i).Send()
+		}
+		if err := rf.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		backups, _ := filepath.Glob(filepath.Join(dir, "app-*.log"))
+		if got, want := len(backups), 2; got != want {
+			t.Errorf("got: %d backups, want: %d", got, want)
+		}
+
+		fi, err := os.Stat(filepath.Join(dir, "app.log"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Size() > 100 {
+			t.Errorf("expected file size <= 100, got %d", fi.Size())
+		}
+	})
+
+	t.Run("compress", func(t *testing.T) {
+		dir := t.TempDir()
+		rf := logger.NewRotatingFile(filepath.Join(dir, "app.log"))
+		rf.Compress = true
+
+		jsonlog := logger.NewJSON(rf)
+		jsonlog.Info("One").Send()
+		if err := rf.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+		jsonlog.Info("Two").Send()
+		if err := rf.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		backups, _ := filepath.Glob(filepath.Join(dir, "app-*.log.gz"))
+		if len(backups) != 1 {
+			t.Fatalf("expected 1 compressed backup, got %d", len(backups))
+		}
+
+		f, err := os.Open(backups[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := string(data), `{"msg":"One","level":"info"}`; got != want {
+			t.Errorf("got: %s, want: %s", got, want)
+		}
+	})
+
+	t.Run("reopen", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+		rf := logger.NewRotatingFile(path)
+		defer rf.Close()
+
+		rf.Write([]byte("one\n"))
+		// Simulate an external tool such as logrotate
+		if err := os.Rename(path, path+".1"); err != nil {
+			t.Fatal(err)
+		}
+		if err := rf.Reopen(); err != nil {
+			t.Fatal(err)
+		}
+		rf.Write([]byte("two\n"))
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := string(data), "two\n"; got != want {
+			t.Errorf("got: %q, want: %q", got, want)
+		}
+	})
+
+	t.Run("shared", func(t *testing.T) {
+		dir := t.TempDir()
+		rf := logger.NewRotatingFile(filepath.Join(dir, "app.log"))
+		rf.MaxSize = 1024
+
+		ml := logger.NewMulti(logger.NewJSON(rf), logger.NewJSON(rf))
+		wg := sync.WaitGroup{}
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					ml.Info("Test").Int("j", j).Send()
+				}
+			}()
+		}
+		wg.Wait()
+		if err := rf.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		files, _ := filepath.Glob(filepath.Join(dir, "app*.log"))
+		total := 0
+		for _, path := range files {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, line := range strings.SplitAfter(string(data), "}") {
+				if line != "" {
+					total++
+				}
+			}
+		}
+		if got, want := total, 8*50*2; got != want {
+			t.Errorf("got: %d events, want: %d", got, want)
+		}
+	})
+}
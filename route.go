@@ -0,0 +1,159 @@
+package logger
+
+import (
+	"fmt"
+	"time"
+)
+
+var _ Logger = (*Route)(nil)
+
+// Route implements the Logger interface by passing only events
+// within a range of levels that match an optional filter to an
+// underlying logger. Events that aren't passed return
+// NopLogBuilder, so they don't cost anything to encode.
+//
+// Routes are meant to be used as the children of a MultiLogger,
+// so that each child can have its own levels, such as a pretty
+// logger at info to the console, a JSON logger at debug to
+// a file, and only errors to a network sink. The MultiLogger
+// doesn't create builders for routes that are disabled at
+// an event's level.
+type Route struct {
+	Logger Logger
+	// MinLevel is the lowest level passed to the logger
+	MinLevel LogLevel
+	// MaxLevel is the highest level passed to the logger.
+	// It only applies if HasMax is set.
+	MaxLevel LogLevel
+	// HasMax enables MaxLevel. If it's not set,
+	// there's no upper bound.
+	HasMax bool
+	// Filter reports whether an event should be passed to the
+	// logger. If it's set, events are collected and passed
+	// when they're sent, so that their fields can be checked.
+	// Filtering out a fatal or panic event prevents the
+	// program from exiting or panicking.
+	Filter func(e *Event) bool
+}
+
+// NewRoute creates and returns a new Route wrapping l that passes
+// events from min to max, inclusive. The level of l is set to min, so that
+// it doesn't filter out any of the passed events.
+func NewRoute(l Logger, min, max LogLevel) *Route {
+	l.SetLevel(min)
+	return &Route{Logger: l, MinLevel: min, MaxLevel: max, HasMax: true}
+}
+
+// Enabled reports whether events with the given level are
+// within the route and logged by the underlying logger
+func (r *Route) Enabled(lvl LogLevel) bool {
+	if lvl < r.MinLevel || (r.HasMax && lvl > r.MaxLevel) {
+		return false
+	}
+	return enabled(r.Logger, lvl)
+}
+
+// NoPanic prevents the logger from panicking on panic events
+func (r *Route) NoPanic() {
+	r.Logger.NoPanic()
+}
+
+// NoExit prevents the logger from exiting on fatal events
+func (r *Route) NoExit() {
+	r.Logger.NoExit()
+}
+
+// Now returns the current time using
+// the clock of the underlying logger
+func (r *Route) Now() time.Time {
+	return clockOf(r.Logger)()
+}
+
+// SetLevel sets the minimum level of the
+// route and the level of the logger
+func (r *Route) SetLevel(l LogLevel) {
+	r.MinLevel = l
+	r.Logger.SetLevel(l)
+}
+
+// Debug creates a new debug event with the given message
+func (r *Route) Debug(msg string) LogBuilder {
+	return newRouteLogBuilder(r, msg, LogLevelDebug)
+}
+
+// Debugf creates a new debug event with the formatted message
+func (r *Route) Debugf(format string, v ...any) LogBuilder {
+	return newRouteLogBuilder(r, fmt.Sprintf(format, v...), LogLevelDebug)
+}
+
+// Info creates a new info event with the given message
+func (r *Route) Info(msg string) LogBuilder {
+	return newRouteLogBuilder(r, msg, LogLevelInfo)
+}
+
+// Infof creates a new info event with the formatted message
+func (r *Route) Infof(format string, v ...any) LogBuilder {
+	return newRouteLogBuilder(r, fmt.Sprintf(format, v...), LogLevelInfo)
+}
+
+// Warn creates a new warn event with the given message
+func (r *Route) Warn(msg string) LogBuilder {
+	return newRouteLogBuilder(r, msg, LogLevelWarn)
+}
+
+// Warnf creates a new warn event with the formatted message
+func (r *Route) Warnf(format string, v ...any) LogBuilder {
+	return newRouteLogBuilder(r, fmt.Sprintf(format, v...), LogLevelWarn)
+}
+
+// Error creates a new error event with the given message
+func (r *Route) Error(msg string) LogBuilder {
+	return newRouteLogBuilder(r, msg, LogLevelError)
+}
+
+// Errorf creates a new error event with the formatted message
+func (r *Route) Errorf(format string, v ...any) LogBuilder {
+	return newRouteLogBuilder(r, fmt.Sprintf(format, v...), LogLevelError)
+}
+
+// Fatal creates a new fatal event with the given message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (r *Route) Fatal(msg string) LogBuilder {
+	return newRouteLogBuilder(r, msg, LogLevelFatal)
+}
+
+// Fatalf creates a new fatal event with the formatted message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (r *Route) Fatalf(format string, v ...any) LogBuilder {
+	return newRouteLogBuilder(r, fmt.Sprintf(format, v...), LogLevelFatal)
+}
+
+// Panic creates a new panic event with the given message
+//
+// When sent, panic events will cause a panic
+func (r *Route) Panic(msg string) LogBuilder {
+	return newRouteLogBuilder(r, msg, LogLevelPanic)
+}
+
+// Panicf creates a new panic event with the formatted message
+//
+// When sent, panic events will cause a panic
+func (r *Route) Panicf(format string, v ...any) LogBuilder {
+	return newRouteLogBuilder(r, fmt.Sprintf(format, v...), LogLevelPanic)
+}
+
+func newRouteLogBuilder(r *Route, msg string, lvl LogLevel) LogBuilder {
+	if !r.Enabled(lvl) {
+		return NopLogBuilder{}
+	}
+	if r.Filter == nil {
+		return logBuilder(r.Logger, lvl, msg)
+	}
+	return newEventLogBuilder(lvl, msg, r.Now, func(e *Event) {
+		if r.Filter(e) {
+			e.Replay(r.Logger)
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package logger_test
+
+import (
+	"testing"
+
+	"go.elara.ws/logger"
+	"go.elara.ws/logger/logtest"
+)
+
+// countingObserver is an Observer that counts
+// how many debug builders it has created
+type countingObserver struct {
+	*logtest.Observer
+	debugCalls int
+}
+
+func (co *countingObserver) Debug(msg string) logger.LogBuilder {
+	co.debugCalls++
+	return co.Observer.Debug(msg)
+}
+
+func TestRoute(t *testing.T) {
+	t.Run("levels", func(t *testing.T) {
+		console, file, network := logtest.New(), logtest.New(), logtest.New()
+		ml := logger.NewMulti(
+			logger.NewRoute(console, logger.LogLevelInfo, logger.LogLevelPanic),
+			logger.NewRoute(file, logger.LogLevelDebug, logger.LogLevelPanic),
+			logger.NewRoute(network, logger.LogLevelError, logger.LogLevelPanic),
+		)
+
+		ml.Debug("debug").Send()
+		ml.Info("info").Send()
+		ml.Errorf("error %d", 1).Send()
+
+		if got, want := console.Len(), 2; got != want {
+			t.Errorf("got: %d console events, want: %d", got, want)
+		}
+		if got, want := file.Len(), 3; got != want {
+			t.Errorf("got: %d file events, want: %d", got, want)
+		}
+		if got, want := network.Len(), 1; got != want {
+			t.Errorf("got: %d network events, want: %d", got, want)
+		}
+		network.AssertLogged(t, logger.LogLevelError, "error 1")
+	})
+
+	t.Run("max-level", func(t *testing.T) {
+		o := logtest.New()
+		r := logger.NewRoute(o, logger.LogLevelDebug, logger.LogLevelInfo)
+
+		r.Info("info").Send()
+		r.Warn("warn").Send()
+
+		if got, want := o.Len(), 1; got != want {
+			t.Errorf("got: %d events, want: %d", got, want)
+		}
+		o.AssertNotLogged(t, logger.LogLevelWarn, "warn")
+	})
+
+	t.Run("no-max-level", func(t *testing.T) {
+		o := logtest.New()
+		r := &logger.Route{Logger: o, MinLevel: logger.LogLevelInfo}
+
+		r.Debug("debug").Send()
+		r.Error("error").Send()
+
+		if got, want := o.Len(), 1; got != want {
+			t.Errorf("got: %d events, want: %d", got, want)
+		}
+		o.AssertLogged(t, logger.LogLevelError, "error")
+	})
+
+	t.Run("debug-only", func(t *testing.T) {
+		o := logtest.New()
+		r := logger.NewRoute(o, logger.LogLevelDebug, logger.LogLevelDebug)
+
+		r.Debug("debug").Send()
+		r.Info("info").Send()
+		r.Error("error").Send()
+
+		if got, want := o.Len(), 1; got != want {
+			t.Errorf("got: %d events, want: %d", got, want)
+		}
+		o.AssertLogged(t, logger.LogLevelDebug, "debug")
+	})
+
+	t.Run("multi-set-level", func(t *testing.T) {
+		routed, other := logtest.New(), logtest.New()
+		ml := logger.NewMulti(
+			logger.NewRoute(routed, logger.LogLevelError, logger.LogLevelPanic),
+			other,
+		)
+		ml.SetLevel(logger.LogLevelWarn)
+
+		ml.Info("info").Send()
+		ml.Warn("warn").Send()
+
+		if got, want := routed.Len(), 0; got != want {
+			t.Errorf("got: %d routed events, want: %d", got, want)
+		}
+		if got, want := other.Len(), 1; got != want {
+			t.Errorf("got: %d events, want: %d", got, want)
+		}
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		o := logtest.New()
+		r := logger.NewRoute(o, logger.LogLevelDebug, logger.LogLevelPanic)
+		r.Filter = func(e *logger.Event) bool {
+			for _, f := range e.Fields {
+				if f.Key == "component" && f.Value == "db" {
+					return true
+				}
+			}
+			return false
+		}
+
+		r.Info("query").Str("component", "db").Send()
+		r.Info("request").Str("component", "http").Send()
+
+		if got, want := o.Len(), 1; got != want {
+			t.Fatalf("got: %d events, want: %d", got, want)
+		}
+		o.AssertLogged(t, logger.LogLevelInfo, "query", logtest.Str("component", "db"))
+	})
+
+	t.Run("disabled-not-built", func(t *testing.T) {
+		co := &countingObserver{Observer: logtest.New()}
+		other := logtest.New()
+		ml := logger.NewMulti(
+			logger.NewRoute(co, logger.LogLevelInfo, logger.LogLevelPanic),
+			logger.NewRoute(other, logger.LogLevelDebug, logger.LogLevelPanic),
+		)
+
+		ml.Debug("Test").Send()
+
+		if co.debugCalls != 0 {
+			t.Errorf("got: %d builders for a disabled route, want: 0", co.debugCalls)
+		}
+		if got, want := other.Len(), 1; got != want {
+			t.Errorf("got: %d events, want: %d", got, want)
+		}
+	})
+
+	t.Run("set-level", func(t *testing.T) {
+		o := logtest.New()
+		r := logger.NewRoute(o, logger.LogLevelInfo, logger.LogLevelPanic)
+		r.SetLevel(logger.LogLevelDebug)
+
+		r.Debug("Test").Send()
+		o.AssertLogged(t, logger.LogLevelDebug, "Test")
+	})
+}
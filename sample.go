@@ -0,0 +1,453 @@
+package logger
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var _ Logger = (*Sampler)(nil)
+
+// SamplingStrategy decides which events are logged by a Sampler
+type SamplingStrategy interface {
+	// Sample reports whether an event with the
+	// given level and message should be logged
+	Sample(lvl LogLevel, msg string) bool
+}
+
+// Sampler implements the Logger interface by passing only
+// a sample of events to an underlying logger. Events that
+// are sampled out return NopLogBuilder, so they don't
+// cost anything to encode.
+//
+// An event is logged only if all of the strategies keep it.
+// Fatal and panic events are always logged. For formatted
+// events, the format string is used as the message, so
+// that it doesn't need to be formatted to be sampled.
+type Sampler struct {
+	Logger     Logger
+	Strategies []SamplingStrategy
+
+	dropped [LogLevelPanic + 1]uint64
+}
+
+// NewSampler creates and returns a new Sampler
+// wrapping l that uses the given strategies
+func NewSampler(l Logger, strategies ...SamplingStrategy) *Sampler {
+	return &Sampler{Logger: l, Strategies: strategies}
+}
+
+// Dropped returns the total amount of events
+// that have been sampled out
+func (s *Sampler) Dropped() uint64 {
+	var total uint64
+	for i := range s.dropped {
+		total += atomic.LoadUint64(&s.dropped[i])
+	}
+	return total
+}
+
+// DroppedLevel returns the amount of events with the
+// given level that have been sampled out
+func (s *Sampler) DroppedLevel(lvl LogLevel) uint64 {
+	if int(lvl) >= len(s.dropped) {
+		return 0
+	}
+	return atomic.LoadUint64(&s.dropped[lvl])
+}
+
+// countingStrategy is implemented by strategies that count the
+// events they see. Sampler only counts an event in a strategy if
+// all the other strategies keep it, so that events dropped by one
+// strategy don't use up the counts of another. The time comes
+// from the clock of the Sampler's logger.
+type countingStrategy interface {
+	// peek reports whether an event would be
+	// kept, without counting it
+	peek(lvl LogLevel, msg string, now time.Time) bool
+	// count counts an event and reports whether it's kept
+	count(lvl LogLevel, msg string, now time.Time) bool
+}
+
+// sample reports whether the event should be logged,
+// counting it if it's dropped. Events with levels that the
+// underlying logger doesn't log aren't sampled at all.
+func (s *Sampler) sample(lvl LogLevel, msg string) bool {
+	if !enabled(s.Logger, lvl) {
+		return false
+	}
+	if lvl >= LogLevelFatal {
+		return true
+	}
+
+	// Check every strategy before counting the event in any of
+	// them. If exactly one strategy drops it, that strategy still
+	// counts it, since it's the one that decided.
+	now := s.Now()
+	dropped := -1
+	for i, strategy := range s.Strategies {
+		var keep bool
+		if cs, ok := strategy.(countingStrategy); ok {
+			keep = cs.peek(lvl, msg, now)
+		} else {
+			keep = strategy.Sample(lvl, msg)
+		}
+		if keep {
+			continue
+		}
+		if dropped >= 0 {
+			dropped = len(s.Strategies)
+			break
+		}
+		dropped = i
+	}
+
+	keep := dropped < 0
+	for i, strategy := range s.Strategies {
+		cs, ok := strategy.(countingStrategy)
+		if !ok || (dropped >= 0 && i != dropped) {
+			continue
+		}
+		// Another event may have been counted since peek
+		// was called, so the result of count is final
+		if !cs.count(lvl, msg, now) {
+			keep = false
+		}
+	}
+
+	if !keep {
+		atomic.AddUint64(&s.dropped[lvl], 1)
+	}
+	return keep
+}
+
+// Enabled reports whether events with the given level are
+// logged by the underlying logger. Sampling isn't taken
+// into account.
+func (s *Sampler) Enabled(lvl LogLevel) bool {
+	return enabled(s.Logger, lvl)
+}
+
+// NoPanic prevents the logger from panicking on panic events
+func (s *Sampler) NoPanic() {
+	s.Logger.NoPanic()
+}
+
+// NoExit prevents the logger from exiting on fatal events
+func (s *Sampler) NoExit() {
+	s.Logger.NoExit()
+}
+
+// Now returns the current time using
+// the clock of the underlying logger
+func (s *Sampler) Now() time.Time {
+	return clockOf(s.Logger)()
+}
+
+// SetLevel sets the log level of the logger
+func (s *Sampler) SetLevel(l LogLevel) {
+	s.Logger.SetLevel(l)
+}
+
+// Debug creates a new debug event with the given message
+func (s *Sampler) Debug(msg string) LogBuilder {
+	if !s.sample(LogLevelDebug, msg) {
+		return NopLogBuilder{}
+	}
+	return s.Logger.Debug(msg)
+}
+
+// Debugf creates a new debug event with the formatted message
+func (s *Sampler) Debugf(format string, v ...any) LogBuilder {
+	if !s.sample(LogLevelDebug, format) {
+		return NopLogBuilder{}
+	}
+	return s.Logger.Debugf(format, v...)
+}
+
+// Info creates a new info event with the given message
+func (s *Sampler) Info(msg string) LogBuilder {
+	if !s.sample(LogLevelInfo, msg) {
+		return NopLogBuilder{}
+	}
+	return s.Logger.Info(msg)
+}
+
+// Infof creates a new info event with the formatted message
+func (s *Sampler) Infof(format string, v ...any) LogBuilder {
+	if !s.sample(LogLevelInfo, format) {
+		return NopLogBuilder{}
+	}
+	return s.Logger.Infof(format, v...)
+}
+
+// Warn creates a new warn event with the given message
+func (s *Sampler) Warn(msg string) LogBuilder {
+	if !s.sample(LogLevelWarn, msg) {
+		return NopLogBuilder{}
+	}
+	return s.Logger.Warn(msg)
+}
+
+// Warnf creates a new warn event with the formatted message
+func (s *Sampler) Warnf(format string, v ...any) LogBuilder {
+	if !s.sample(LogLevelWarn, format) {
+		return NopLogBuilder{}
+	}
+	return s.Logger.Warnf(format, v...)
+}
+
+// Error creates a new error event with the given message
+func (s *Sampler) Error(msg string) LogBuilder {
+	if !s.sample(LogLevelError, msg) {
+		return NopLogBuilder{}
+	}
+	return s.Logger.Error(msg)
+}
+
+// Errorf creates a new error event with the formatted message
+func (s *Sampler) Errorf(format string, v ...any) LogBuilder {
+	if !s.sample(LogLevelError, format) {
+		return NopLogBuilder{}
+	}
+	return s.Logger.Errorf(format, v...)
+}
+
+// Fatal creates a new fatal event with the given message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (s *Sampler) Fatal(msg string) LogBuilder {
+	if !s.sample(LogLevelFatal, msg) {
+		return NopLogBuilder{}
+	}
+	return s.Logger.Fatal(msg)
+}
+
+// Fatalf creates a new fatal event with the formatted message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (s *Sampler) Fatalf(format string, v ...any) LogBuilder {
+	if !s.sample(LogLevelFatal, format) {
+		return NopLogBuilder{}
+	}
+	return s.Logger.Fatalf(format, v...)
+}
+
+// Panic creates a new panic event with the given message
+//
+// When sent, panic events will cause a panic
+func (s *Sampler) Panic(msg string) LogBuilder {
+	if !s.sample(LogLevelPanic, msg) {
+		return NopLogBuilder{}
+	}
+	return s.Logger.Panic(msg)
+}
+
+// Panicf creates a new panic event with the formatted message
+//
+// When sent, panic events will cause a panic
+func (s *Sampler) Panicf(format string, v ...any) LogBuilder {
+	if !s.sample(LogLevelPanic, format) {
+		return NopLogBuilder{}
+	}
+	return s.Logger.Panicf(format, v...)
+}
+
+// samplingWindow keeps track of the
+// current interval of a strategy
+type samplingWindow struct {
+	interval time.Duration
+	end      time.Time
+}
+
+// ended reports whether the current interval
+// has ended at the given time
+func (sw *samplingWindow) ended(now time.Time) bool {
+	return !now.Before(sw.end)
+}
+
+// expired reports whether the current interval has
+// ended at the given time, starting a new one if it has
+func (sw *samplingWindow) expired(now time.Time) bool {
+	if !sw.ended(now) {
+		return false
+	}
+	sw.end = now.Add(sw.interval)
+	return true
+}
+
+// DefaultSamplingKeys is the default maximum amount of distinct
+// levels and messages a FirstNSampler counts in an interval
+const DefaultSamplingKeys = 10000
+
+// FirstNSampler logs the first events with each level and message
+// in every interval, and then every Mth event after that.
+type FirstNSampler struct {
+	// MaxKeys is the maximum amount of distinct levels and messages
+	// counted in an interval. When it's reached, the counts are
+	// reset early, so that messages that vary, such as ones built
+	// with Sprintf, don't use an unbounded amount of memory. Zero
+	// means no limit.
+	MaxKeys int
+
+	first      uint64
+	thereafter uint64
+
+	mu     sync.Mutex
+	window samplingWindow
+	counts map[samplingKey]uint64
+}
+
+// samplingKey identifies the events counted together
+type samplingKey struct {
+	lvl LogLevel
+	msg string
+}
+
+// NewFirstNSampler creates and returns a new FirstNSampler that logs
+// the first events with each level and message in every interval, then
+// every thereafter-th event. If thereafter is zero, no further events
+// are logged until the next interval.
+func NewFirstNSampler(first, thereafter int, interval time.Duration) *FirstNSampler {
+	return &FirstNSampler{
+		MaxKeys:    DefaultSamplingKeys,
+		first:      uint64(first),
+		thereafter: uint64(thereafter),
+		window:     samplingWindow{interval: interval},
+		counts:     map[samplingKey]uint64{},
+	}
+}
+
+// Sample implements the SamplingStrategy interface
+func (fs *FirstNSampler) Sample(lvl LogLevel, msg string) bool {
+	return fs.count(lvl, msg, time.Now())
+}
+
+// peek implements the countingStrategy interface
+func (fs *FirstNSampler) peek(lvl LogLevel, msg string, now time.Time) bool {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	var n uint64
+	if !fs.window.ended(now) {
+		n = fs.counts[samplingKey{lvl, msg}]
+	}
+	return fs.keep(n + 1)
+}
+
+// count implements the countingStrategy interface
+func (fs *FirstNSampler) count(lvl LogLevel, msg string, now time.Time) bool {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	key := samplingKey{lvl, msg}
+	if fs.window.expired(now) {
+		fs.counts = map[samplingKey]uint64{}
+	} else if _, ok := fs.counts[key]; !ok && fs.MaxKeys > 0 && len(fs.counts) >= fs.MaxKeys {
+		fs.counts = map[samplingKey]uint64{}
+	}
+
+	fs.counts[key]++
+	return fs.keep(fs.counts[key])
+}
+
+// keep reports whether the nth event with
+// a level and message is kept
+func (fs *FirstNSampler) keep(n uint64) bool {
+	if n <= fs.first {
+		return true
+	}
+	return fs.thereafter > 0 && (n-fs.first)%fs.thereafter == 0
+}
+
+// RandomSampler logs events with a fixed probability
+type RandomSampler struct {
+	// Rate is the probability of an event being
+	// logged, between 0 and 1
+	Rate float64
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewRandomSampler creates and returns a new RandomSampler
+// that logs events with the given probability
+func NewRandomSampler(rate float64) *RandomSampler {
+	return &RandomSampler{
+		Rate: rate,
+		rng:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Sample implements the SamplingStrategy interface
+func (rs *RandomSampler) Sample(lvl LogLevel, msg string) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return rs.rng.Float64() < rs.Rate
+}
+
+// LevelBudgetSampler logs at most a fixed amount of
+// events of each level in every interval. Levels
+// without a budget aren't limited.
+type LevelBudgetSampler struct {
+	budgets map[LogLevel]uint64
+
+	mu     sync.Mutex
+	window samplingWindow
+	counts [LogLevelPanic + 1]uint64
+}
+
+// NewLevelBudgetSampler creates and returns a new LevelBudgetSampler
+// that logs at most the given amount of events of each level in
+// every interval
+func NewLevelBudgetSampler(interval time.Duration, budgets map[LogLevel]int) *LevelBudgetSampler {
+	lbs := &LevelBudgetSampler{
+		budgets: make(map[LogLevel]uint64, len(budgets)),
+		window:  samplingWindow{interval: interval},
+	}
+	for lvl, budget := range budgets {
+		lbs.budgets[lvl] = uint64(budget)
+	}
+	return lbs
+}
+
+// Sample implements the SamplingStrategy interface
+func (lbs *LevelBudgetSampler) Sample(lvl LogLevel, msg string) bool {
+	return lbs.count(lvl, msg, time.Now())
+}
+
+// peek implements the countingStrategy interface
+func (lbs *LevelBudgetSampler) peek(lvl LogLevel, msg string, now time.Time) bool {
+	budget, ok := lbs.budgets[lvl]
+	if !ok || int(lvl) >= len(lbs.counts) {
+		return true
+	}
+
+	lbs.mu.Lock()
+	defer lbs.mu.Unlock()
+
+	var n uint64
+	if !lbs.window.ended(now) {
+		n = lbs.counts[lvl]
+	}
+	return n < budget
+}
+
+// count implements the countingStrategy interface
+func (lbs *LevelBudgetSampler) count(lvl LogLevel, msg string, now time.Time) bool {
+	budget, ok := lbs.budgets[lvl]
+	if !ok || int(lvl) >= len(lbs.counts) {
+		return true
+	}
+
+	lbs.mu.Lock()
+	defer lbs.mu.Unlock()
+
+	if lbs.window.expired(now) {
+		lbs.counts = [LogLevelPanic + 1]uint64{}
+	}
+
+	lbs.counts[lvl]++
+	return lbs.counts[lvl] <= budget
+}
@@ -0,0 +1,155 @@
+package logger_test
+
+import (
+	"testing"
+	"time"
+
+	"go.elara.ws/logger"
+	"go.elara.ws/logger/logtest"
+)
+
+func TestSampler(t *testing.T) {
+	t.Run("first-n", func(t *testing.T) {
+		now := time.Now()
+		fs := logger.NewFirstNSampler(2, 3, time.Second)
+
+		obs := logtest.New()
+		obs.Clock = func() time.Time { return now }
+		s := logger.NewSampler(obs, fs)
+
+		for i := 0; i < 10; i++ {
+			s.Info("Request").Int("i", i).Send()
+			s.Infof("Request %d", i).Send()
+		}
+
+		// The 1st, 2nd, 5th and 8th events are logged. Formatted
+		// events are sampled by their format string.
+		if got, want := obs.Count(logger.LogLevelInfo, "Request"), 4; got != want {
+			t.Errorf("got: %d events, want: %d", got, want)
+		}
+		if got, want := len(obs.FilterMessage("Request 7")), 1; got != want {
+			t.Errorf("got: %d events, want: %d", got, want)
+		}
+		if got, want := s.Dropped(), uint64(12); got != want {
+			t.Errorf("got: %d dropped, want: %d", got, want)
+		}
+
+		now = now.Add(time.Second)
+		s.Info("Request").Send()
+		if got, want := obs.Count(logger.LogLevelInfo, "Request"), 5; got != want {
+			t.Errorf("got: %d events after interval, want: %d", got, want)
+		}
+	})
+
+	t.Run("random", func(t *testing.T) {
+		obs := logtest.New()
+		s := logger.NewSampler(obs, logger.NewRandomSampler(0.5))
+
+		for i := 0; i < 1000; i++ {
+			s.Info("Test").Send()
+		}
+
+		if n := obs.Len(); n < 350 || n > 650 {
+			t.Errorf("expected about 500 events, got: %d", n)
+		}
+		if got, want := uint64(obs.Len())+s.Dropped(), uint64(1000); got != want {
+			t.Errorf("got: %d, want: %d", got, want)
+		}
+
+		s = logger.NewSampler(obs, logger.NewRandomSampler(0))
+		if _, ok := s.Info("Test").(logger.NopLogBuilder); !ok {
+			t.Error("expected sampled out event to return NopLogBuilder")
+		}
+	})
+
+	t.Run("level-budget", func(t *testing.T) {
+		now := time.Now()
+		lbs := logger.NewLevelBudgetSampler(time.Second, map[logger.LogLevel]int{
+			logger.LogLevelInfo: 3,
+		})
+
+		obs := logtest.New()
+		obs.Clock = func() time.Time { return now }
+		obs.NoPanic()
+		s := logger.NewSampler(obs, lbs)
+
+		for i := 0; i < 5; i++ {
+			s.Info("Info").Send()
+			s.Warn("Warn").Send()
+		}
+		s.Panic("Panic").Send()
+
+		if got, want := len(obs.FilterLevel(logger.LogLevelInfo)), 3; got != want {
+			t.Errorf("got: %d info events, want: %d", got, want)
+		}
+		if got, want := len(obs.FilterLevel(logger.LogLevelWarn)), 5; got != want {
+			t.Errorf("got: %d warn events, want: %d", got, want)
+		}
+		if got, want := s.DroppedLevel(logger.LogLevelInfo), uint64(2); got != want {
+			t.Errorf("got: %d dropped, want: %d", got, want)
+		}
+		if got, want := len(obs.FilterLevel(logger.LogLevelPanic)), 1; got != want {
+			t.Errorf("got: %d panic events, want: %d", got, want)
+		}
+
+		now = now.Add(time.Second)
+		s.Info("Info").Send()
+		if got, want := len(obs.FilterLevel(logger.LogLevelInfo)), 4; got != want {
+			t.Errorf("got: %d info events after interval, want: %d", got, want)
+		}
+	})
+
+	t.Run("level-first", func(t *testing.T) {
+		obs := logtest.New()
+		obs.Level = logger.LogLevelWarn
+		s := logger.NewSampler(obs, logger.NewFirstNSampler(1, 0, time.Hour))
+
+		s.Info("Test").Send()
+		s.Info("Test").Send()
+		if got := s.Dropped(); got != 0 {
+			t.Errorf("got: %d dropped, want: 0", got)
+		}
+
+		obs.Level = logger.LogLevelDebug
+		s.Info("Test").Send()
+		obs.AssertLogged(t, logger.LogLevelInfo, "Test")
+	})
+
+	t.Run("commit", func(t *testing.T) {
+		now := time.Now()
+		obs := logtest.New()
+		obs.Clock = func() time.Time { return now }
+		s := logger.NewSampler(obs,
+			logger.NewFirstNSampler(1, 0, time.Hour),
+			logger.NewLevelBudgetSampler(time.Second, map[logger.LogLevel]int{logger.LogLevelInfo: 1}),
+		)
+
+		s.Info("a").Send()
+		// Dropped by the budget, so it must not
+		// be counted by the first sampler
+		s.Info("b").Send()
+		now = now.Add(time.Second)
+		s.Info("b").Send()
+
+		if got, want := obs.Count(logger.LogLevelInfo, "b"), 1; got != want {
+			t.Errorf("got: %d events, want: %d", got, want)
+		}
+	})
+
+	t.Run("max-keys", func(t *testing.T) {
+		obs := logtest.New()
+		fs := logger.NewFirstNSampler(1, 0, time.Hour)
+		fs.MaxKeys = 2
+		s := logger.NewSampler(obs, fs)
+
+		for _, msg := range []string{"a", "b", "a", "c", "a"} {
+			s.Info(msg).Send()
+		}
+
+		// The counts are reset when c is seen, so
+		// the last a is logged again
+		if got, want := obs.Count(logger.LogLevelInfo, "a"), 2; got != want {
+			t.Errorf("got: %d events, want: %d", got, want)
+		}
+	})
+}
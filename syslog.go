@@ -0,0 +1,452 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ Logger = (*SyslogLogger)(nil)
+
+// syslogSeverities maps log levels to syslog severities
+var syslogSeverities = [...]int{
+	LogLevelDebug: 7,
+	LogLevelInfo:  6,
+	LogLevelWarn:  4,
+	LogLevelError: 3,
+	LogLevelFatal: 2,
+	LogLevelPanic: 1,
+}
+
+// SyslogFacility represents a syslog facility
+type SyslogFacility uint8
+
+// Syslog facilities
+const (
+	FacilityKern SyslogFacility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLPR
+	FacilityNews
+	FacilityUUCP
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFTP
+	_
+	_
+	_
+	_
+	FacilityLocal0
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// SyslogFormat represents the format of syslog messages
+type SyslogFormat uint8
+
+// Syslog formats
+const (
+	// RFC5424 is the modern syslog format, which
+	// supports structured data
+	RFC5424 SyslogFormat = iota
+	// RFC3164 is the legacy BSD syslog format.
+	// Fields are appended to the message as key=value pairs.
+	RFC3164
+)
+
+// SyslogLogger implements the Logger interface
+// using syslog messages. Every message is written
+// to the output using a single call to Write.
+type SyslogLogger struct {
+	Out   io.Writer
+	Level LogLevel
+
+	Format   SyslogFormat
+	Facility SyslogFacility
+
+	Hostname string
+	AppName  string
+	ProcID   string
+	MsgID    string
+
+	// SDID is the ID of the RFC 5424 structured data
+	// element that contains the event's fields
+	SDID string
+
+	ErrorHandling
+	Environment
+
+	noPanic bool
+	noExit  bool
+}
+
+// NewSyslog creates and returns a new SyslogLogger.
+// The hostname, app name and process ID are set
+// based on the current process.
+func NewSyslog(out io.Writer) *SyslogLogger {
+	host, _ := os.Hostname()
+	return &SyslogLogger{
+		Out:      out,
+		Level:    LogLevelInfo,
+		Format:   RFC5424,
+		Facility: FacilityUser,
+		Hostname: host,
+		AppName:  filepath.Base(os.Args[0]),
+		ProcID:   strconv.Itoa(os.Getpid()),
+		SDID:     "fields@32473",
+	}
+}
+
+// NoPanic prevents the logger from panicking on panic events
+func (sl *SyslogLogger) NoPanic() {
+	sl.noPanic = true
+}
+
+// NoExit prevents the logger from exiting on fatal events
+func (sl *SyslogLogger) NoExit() {
+	sl.noExit = true
+}
+
+// SetLevel sets the log level of the logger
+func (sl *SyslogLogger) SetLevel(l LogLevel) {
+	sl.Level = l
+}
+
+// Enabled reports whether events with the given level are logged
+func (sl *SyslogLogger) Enabled(lvl LogLevel) bool {
+	return sl.Out != io.Discard && lvl >= sl.Level
+}
+
+// Debug creates a new debug event with the given message
+func (sl *SyslogLogger) Debug(msg string) LogBuilder {
+	return newSyslogLogBuilder(sl, msg, LogLevelDebug)
+}
+
+// Debugf creates a new debug event with the formatted message
+func (sl *SyslogLogger) Debugf(format string, v ...any) LogBuilder {
+	return newSyslogLogBuilder(sl, fmt.Sprintf(format, v...), LogLevelDebug)
+}
+
+// Info creates a new info event with the given message
+func (sl *SyslogLogger) Info(msg string) LogBuilder {
+	return newSyslogLogBuilder(sl, msg, LogLevelInfo)
+}
+
+// Infof creates a new info event with the formatted message
+func (sl *SyslogLogger) Infof(format string, v ...any) LogBuilder {
+	return newSyslogLogBuilder(sl, fmt.Sprintf(format, v...), LogLevelInfo)
+}
+
+// Warn creates a new warn event with the given message
+func (sl *SyslogLogger) Warn(msg string) LogBuilder {
+	return newSyslogLogBuilder(sl, msg, LogLevelWarn)
+}
+
+// Warnf creates a new warn event with the formatted message
+func (sl *SyslogLogger) Warnf(format string, v ...any) LogBuilder {
+	return newSyslogLogBuilder(sl, fmt.Sprintf(format, v...), LogLevelWarn)
+}
+
+// Error creates a new error event with the given message
+func (sl *SyslogLogger) Error(msg string) LogBuilder {
+	return newSyslogLogBuilder(sl, msg, LogLevelError)
+}
+
+// Errorf creates a new error event with the formatted message
+func (sl *SyslogLogger) Errorf(format string, v ...any) LogBuilder {
+	return newSyslogLogBuilder(sl, fmt.Sprintf(format, v...), LogLevelError)
+}
+
+// Fatal creates a new fatal event with the given message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (sl *SyslogLogger) Fatal(msg string) LogBuilder {
+	return newSyslogLogBuilder(sl, msg, LogLevelFatal)
+}
+
+// Fatalf creates a new fatal event with the formatted message
+//
+// When sent, fatal events will cause a call to os.Exit(1)
+func (sl *SyslogLogger) Fatalf(format string, v ...any) LogBuilder {
+	return newSyslogLogBuilder(sl, fmt.Sprintf(format, v...), LogLevelFatal)
+}
+
+// Panic creates a new panic event with the given message
+//
+// When sent, panic events will cause a panic
+func (sl *SyslogLogger) Panic(msg string) LogBuilder {
+	return newSyslogLogBuilder(sl, msg, LogLevelPanic)
+}
+
+// Panicf creates a new panic event with the formatted message
+//
+// When sent, panic events will cause a panic
+func (sl *SyslogLogger) Panicf(format string, v ...any) LogBuilder {
+	return newSyslogLogBuilder(sl, fmt.Sprintf(format, v...), LogLevelPanic)
+}
+
+func newSyslogLogBuilder(sl *SyslogLogger, msg string, lvl LogLevel) LogBuilder {
+	if !sl.Enabled(lvl) {
+		return NopLogBuilder{}
+	}
+	return newEventLogBuilder(lvl, msg, sl.Now, sl.send)
+}
+
+// LogEvent logs a collected event, keeping its time
+func (sl *SyslogLogger) LogEvent(e *Event) {
+	if sl.Enabled(e.Level) {
+		sl.send(e)
+	}
+}
+
+// send encodes the event as a syslog message
+// and writes it to the output
+func (sl *SyslogLogger) send(e *Event) {
+	buf := &bytes.Buffer{}
+	pri := int(sl.Facility)*8 + syslogSeverities[e.Level]
+
+	if sl.Format == RFC3164 {
+		sl.writeRFC3164(buf, pri, e)
+	} else {
+		sl.writeRFC5424(buf, pri, e)
+	}
+	sl.write(sl.Out, buf.Bytes())
+
+	if e.Level == LogLevelFatal && !sl.noExit {
+		sl.exit()
+	} else if e.Level == LogLevelPanic && !sl.noPanic {
+		sl.doPanic(NewPanicEvent(e))
+	}
+}
+
+// writeRFC5424 writes the event to the buffer as an RFC 5424 message
+func (sl *SyslogLogger) writeRFC5424(buf *bytes.Buffer, pri int, e *Event) {
+	buf.WriteByte('<')
+	buf.WriteString(strconv.Itoa(pri))
+	buf.WriteString(">1 ")
+	buf.WriteString(e.Time.Format("2006-01-02T15:04:05.000000Z07:00"))
+	buf.WriteByte(' ')
+	writeSyslogHeader(buf, sl.Hostname, 255)
+	buf.WriteByte(' ')
+	writeSyslogHeader(buf, sl.AppName, 48)
+	buf.WriteByte(' ')
+	writeSyslogHeader(buf, sl.ProcID, 128)
+	buf.WriteByte(' ')
+	writeSyslogHeader(buf, sl.MsgID, 32)
+	buf.WriteByte(' ')
+
+	if len(e.Fields) == 0 {
+		buf.WriteByte('-')
+	} else {
+		buf.WriteByte('[')
+		writeSyslogName(buf, sl.SDID)
+		for _, f := range e.Fields {
+			buf.WriteByte(' ')
+			writeSyslogName(buf, f.Key)
+			buf.WriteString(`="`)
+			writeSyslogParam(buf, f.ValueString())
+			buf.WriteByte('"')
+		}
+		buf.WriteByte(']')
+	}
+
+	if e.Message != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(e.Message)
+	}
+}
+
+// writeRFC3164 writes the event to the buffer as an RFC 3164 message
+func (sl *SyslogLogger) writeRFC3164(buf *bytes.Buffer, pri int, e *Event) {
+	buf.WriteByte('<')
+	buf.WriteString(strconv.Itoa(pri))
+	buf.WriteByte('>')
+	buf.WriteString(e.Time.Format(time.Stamp))
+	buf.WriteByte(' ')
+	writeSyslogHeader(buf, sl.Hostname, 255)
+	buf.WriteByte(' ')
+	buf.WriteString(sl.AppName)
+	if sl.ProcID != "" {
+		buf.WriteByte('[')
+		buf.WriteString(sl.ProcID)
+		buf.WriteByte(']')
+	}
+	buf.WriteString(": ")
+	buf.WriteString(e.Message)
+
+	for _, f := range e.Fields {
+		buf.WriteByte(' ')
+		buf.WriteString(f.Key)
+		buf.WriteByte('=')
+		buf.WriteString(strconv.Quote(f.ValueString()))
+	}
+}
+
+// writeSyslogHeader writes a header field to the buffer,
+// replacing it with the nil value if it's empty and
+// removing any characters that aren't allowed
+func writeSyslogHeader(buf *bytes.Buffer, s string, maxLen int) {
+	if s == "" {
+		buf.WriteByte('-')
+		return
+	}
+	if len(s) > maxLen {
+		s = s[:maxLen]
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c > 32 && c < 127 {
+			buf.WriteByte(c)
+		}
+	}
+}
+
+// writeSyslogName writes an SD-ID or PARAM-NAME to the buffer,
+// replacing any characters that aren't allowed with underscores
+func writeSyslogName(buf *bytes.Buffer, s string) {
+	if len(s) > 32 {
+		s = s[:32]
+	}
+	if s == "" {
+		buf.WriteByte('_')
+		return
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= 32 || c >= 127 || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		buf.WriteByte(c)
+	}
+}
+
+// writeSyslogParam writes a PARAM-VALUE to the buffer,
+// escaping the characters required by RFC 5424
+func writeSyslogParam(buf *bytes.Buffer, s string) {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\', ']':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+}
+
+// ErrNoSyslog is returned by NewSyslogWriter when no local
+// syslog socket could be found
+var ErrNoSyslog = errors.New("no local syslog socket found")
+
+// syslogSockets are the paths where the local
+// syslog socket may be found
+var syslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogWriter is an io.Writer that sends each write as
+// a single syslog message. It's safe for concurrent use.
+//
+// Messages sent over TCP use octet-counting framing
+// as described in RFC 6587. If a write fails, the
+// connection is re-established and the write
+// is retried once.
+type SyslogWriter struct {
+	network string
+	addr    string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogWriter creates a new SyslogWriter that sends
+// messages to the given address. The network may be
+// "udp", "tcp", "unix" or "unixgram". If both network
+// and addr are empty, the local syslog socket is used.
+func NewSyslogWriter(network, addr string) (*SyslogWriter, error) {
+	sw := &SyslogWriter{network: network, addr: addr}
+	if err := sw.connect(); err != nil {
+		return nil, err
+	}
+	return sw, nil
+}
+
+// connect establishes a connection to the syslog server
+func (sw *SyslogWriter) connect() error {
+	if sw.network != "" || sw.addr != "" {
+		conn, err := net.Dial(sw.network, sw.addr)
+		if err != nil {
+			return err
+		}
+		sw.conn = conn
+		return nil
+	}
+
+	for _, path := range syslogSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.Dial(network, path)
+			if err == nil {
+				sw.conn = conn
+				return nil
+			}
+		}
+	}
+	return ErrNoSyslog
+}
+
+// Write sends p as a single syslog message
+func (sw *SyslogWriter) Write(p []byte) (int, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	msg := p
+	if strings.HasPrefix(sw.network, "tcp") {
+		msg = make([]byte, 0, len(p)+8)
+		msg = strconv.AppendInt(msg, int64(len(p)), 10)
+		msg = append(msg, ' ')
+		msg = append(msg, p...)
+	}
+
+	if sw.conn != nil {
+		if _, err := sw.conn.Write(msg); err == nil {
+			return len(p), nil
+		}
+		sw.conn.Close()
+		sw.conn = nil
+	}
+
+	if err := sw.connect(); err != nil {
+		return 0, err
+	}
+	if _, err := sw.conn.Write(msg); err != nil {
+		sw.conn.Close()
+		sw.conn = nil
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the connection to the syslog server
+func (sw *SyslogWriter) Close() error {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if sw.conn == nil {
+		return nil
+	}
+	err := sw.conn.Close()
+	sw.conn = nil
+	return err
+}
@@ -0,0 +1,176 @@
+package logger_test
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.elara.ws/logger"
+)
+
+func newTestSyslog(out *bytes.Buffer) *logger.SyslogLogger {
+	syslog := logger.NewSyslog(out)
+	syslog.Hostname = "host"
+	syslog.AppName = "app"
+	syslog.ProcID = "123"
+	syslog.MsgID = "msg"
+	return syslog
+}
+
+func TestSyslog(t *testing.T) {
+	t.Run("rfc5424", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		syslog := newTestSyslog(out)
+		syslog.Facility = logger.FacilityLocal0
+
+		syslog.Warn("Test").
+			Int("n", 1234).
+			Str("quoted", `a "b" [c]`).
+			Send()
+
+		re := regexp.MustCompile(`^<132>1 \S+ host app 123 msg \[fields@32473 n="1234" quoted="a \\"b\\" \[c\\]"\] Test$`)
+		if got := out.String(); !re.MatchString(got) {
+			t.Errorf("got: %s, want match for: %s", got, re)
+		}
+	})
+
+	t.Run("rfc5424-no-fields", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		syslog := newTestSyslog(out)
+		syslog.MsgID = ""
+
+		syslog.Info("Test").Send()
+
+		re := regexp.MustCompile(`^<14>1 \S+ host app 123 - - Test$`)
+		if got := out.String(); !re.MatchString(got) {
+			t.Errorf("got: %s, want match for: %s", got, re)
+		}
+	})
+
+	t.Run("rfc3164", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		syslog := newTestSyslog(out)
+		syslog.Format = logger.RFC3164
+
+		syslog.Error("Test").Int("n", 1234).Send()
+
+		re := regexp.MustCompile(`^<11>\w{3} [ \d]\d \d\d:\d\d:\d\d host app\[123\]: Test n="1234"$`)
+		if got := out.String(); !re.MatchString(got) {
+			t.Errorf("got: %s, want match for: %s", got, re)
+		}
+	})
+
+	t.Run("udp", func(t *testing.T) {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+
+		sw, err := logger.NewSyslogWriter("udp", conn.LocalAddr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sw.Close()
+
+		logger.NewSyslog(sw).Info("Test").Send()
+
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		buf := make([]byte, 1024)
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(buf[:n]); !strings.HasSuffix(got, " Test") {
+			t.Errorf("unexpected message: %s", got)
+		}
+	})
+
+	t.Run("tcp-framing", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ln.Close()
+
+		sw, err := logger.NewSyslogWriter("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sw.Close()
+
+		conn, err := ln.Accept()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+
+		syslog := logger.NewSyslog(sw)
+		syslog.Info("One").Send()
+		syslog.Info("Two").Send()
+
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		br := bufio.NewReader(conn)
+		for _, want := range []string{" One", " Two"} {
+			lenStr, err := br.ReadString(' ')
+			if err != nil {
+				t.Fatal(err)
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(lenStr))
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(br, msg); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(string(msg), want) {
+				t.Errorf("unexpected message: %s", msg)
+			}
+		}
+	})
+
+	t.Run("unixgram-reconnect", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "log.sock")
+		listen := func() net.PacketConn {
+			conn, err := net.ListenPacket("unixgram", path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return conn
+		}
+
+		conn := listen()
+		sw, err := logger.NewSyslogWriter("unixgram", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sw.Close()
+
+		// Simulate a restart of the syslog daemon
+		conn.Close()
+		os.Remove(path)
+		conn = listen()
+		defer conn.Close()
+
+		logger.NewSyslog(sw).Info("Test").Send()
+
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		buf := make([]byte, 1024)
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(buf[:n]); !strings.HasSuffix(got, " Test") {
+			t.Errorf("unexpected message: %s", got)
+		}
+	})
+}
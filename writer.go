@@ -3,6 +3,7 @@ package logger
 import (
 	"bytes"
 	"io"
+	"sync"
 )
 
 // writer combines a buffer and a writer,
@@ -20,3 +21,63 @@ func (w writer) Flush() error {
 	_, err := io.Copy(w.w, w.Buffer)
 	return err
 }
+
+// ErrorHandling contains options for handling errors that
+// occur while writing events to a logger's output. It's
+// embedded in loggers that write to an io.Writer.
+type ErrorHandling struct {
+	// ErrorHandler is called with any error
+	// that occurs while writing an event
+	ErrorHandler func(error)
+
+	// Fallback receives any events that couldn't be
+	// written to the logger's output, such as os.Stderr
+	Fallback io.Writer
+
+	mu     sync.Mutex
+	errors uint64
+}
+
+// WriteErrors returns the amount of events
+// that couldn't be written to the output
+func (eh *ErrorHandling) WriteErrors() uint64 {
+	eh.mu.Lock()
+	defer eh.mu.Unlock()
+	return eh.errors
+}
+
+// flush flushes w and handles any errors that occur
+func (eh *ErrorHandling) flush(w writer) {
+	// Keep a reference to the whole event, since
+	// a failed write may have consumed part of it
+	data := w.Bytes()
+	if err := w.Flush(); err != nil {
+		eh.handleWriteError(err, data)
+	}
+}
+
+// write writes data to out and handles any errors that occur
+func (eh *ErrorHandling) write(out io.Writer, data []byte) {
+	n, err := out.Write(data)
+	if err == nil && n < len(data) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		eh.handleWriteError(err, data)
+	}
+}
+
+// handleWriteError counts the error, writes the event to the
+// fallback writer, and passes the error to the error handler
+func (eh *ErrorHandling) handleWriteError(err error, data []byte) {
+	eh.mu.Lock()
+	eh.errors++
+	eh.mu.Unlock()
+
+	if eh.Fallback != nil {
+		eh.Fallback.Write(data)
+	}
+	if eh.ErrorHandler != nil {
+		eh.ErrorHandler(err)
+	}
+}
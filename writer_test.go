@@ -0,0 +1,110 @@
+package logger_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.elara.ws/logger"
+)
+
+var errWriteFailed = errors.New("write failed")
+
+// failingWriter is an io.Writer that always fails
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errWriteFailed
+}
+
+// shortWriter is an io.Writer that only writes half of its input
+type shortWriter struct{}
+
+func (shortWriter) Write(p []byte) (int, error) {
+	return len(p) / 2, nil
+}
+
+func TestWriteErrors(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		fallback := &bytes.Buffer{}
+		var handled []error
+
+		jl := logger.NewJSON(failingWriter{})
+		jl.Fallback = fallback
+		jl.ErrorHandler = func(err error) { handled = append(handled, err) }
+
+		jl.Info("Test").Str("a", "b").Send()
+		jl.Info("Test 2").Send()
+
+		if got, want := jl.WriteErrors(), uint64(2); got != want {
+			t.Errorf("got: %d errors, want: %d", got, want)
+		}
+		if got, want := len(handled), 2; got != want {
+			t.Fatalf("got: %d handled errors, want: %d", got, want)
+		}
+		if !errors.Is(handled[0], errWriteFailed) {
+			t.Errorf("unexpected error: %v", handled[0])
+		}
+		if got := fallback.String(); !strings.Contains(got, `"msg":"Test"`) || !strings.Contains(got, `"msg":"Test 2"`) {
+			t.Errorf("expected both events in fallback, got: %s", got)
+		}
+	})
+
+	t.Run("pretty", func(t *testing.T) {
+		fallback := &bytes.Buffer{}
+		pl := logger.NewPretty(failingWriter{})
+		pl.Fallback = fallback
+
+		pl.Info("Test").Send()
+
+		if got, want := pl.WriteErrors(), uint64(1); got != want {
+			t.Errorf("got: %d errors, want: %d", got, want)
+		}
+		if got := fallback.String(); !strings.Contains(got, "Test") {
+			t.Errorf("expected event in fallback, got: %s", got)
+		}
+	})
+
+	t.Run("cli", func(t *testing.T) {
+		fallback := &bytes.Buffer{}
+		cl := logger.NewCLI(failingWriter{})
+		cl.Fallback = fallback
+
+		cl.Info("Test").Send()
+
+		if got, want := cl.WriteErrors(), uint64(1); got != want {
+			t.Errorf("got: %d errors, want: %d", got, want)
+		}
+		if got := fallback.String(); !strings.Contains(got, "Test") {
+			t.Errorf("expected event in fallback, got: %s", got)
+		}
+	})
+
+	t.Run("short-write", func(t *testing.T) {
+		var handled error
+		el := logger.NewECS(shortWriter{})
+		el.ErrorHandler = func(err error) { handled = err }
+
+		el.Info("Test").Send()
+
+		if got, want := el.WriteErrors(), uint64(1); got != want {
+			t.Errorf("got: %d errors, want: %d", got, want)
+		}
+		if handled == nil {
+			t.Error("expected error handler to be called")
+		}
+	})
+
+	t.Run("no-errors", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		jl := logger.NewJSON(buf)
+		jl.ErrorHandler = func(err error) { t.Errorf("unexpected error: %v", err) }
+
+		jl.Info("Test").Send()
+
+		if got, want := jl.WriteErrors(), uint64(0); got != want {
+			t.Errorf("got: %d errors, want: %d", got, want)
+		}
+	})
+}